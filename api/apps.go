@@ -0,0 +1,19 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// ListAppFunctions returns the config of each function in the given app as last
+// synced, in the format sent by SDKs when syncing.  If the app hasn't been
+// synced, this returns an error matching ErrNotFound.
+func (c *Client) ListAppFunctions(ctx context.Context, appID string) ([]json.RawMessage, error) {
+	fns := []json.RawMessage{}
+	if _, err := c.do(ctx, http.MethodGet, "/v1/apps/"+url.PathEscape(appID)+"/functions", nil, nil, &fns); err != nil {
+		return nil, err
+	}
+	return fns, nil
+}
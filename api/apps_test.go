@@ -0,0 +1,33 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAppFunctions(t *testing.T) {
+	r := require.New(t)
+
+	c := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		r.Equal(http.MethodGet, req.Method)
+
+		switch req.URL.Path {
+		case "/v1/apps/my-app/functions":
+			_, _ = w.Write([]byte(`{"data":[{"id":"my-app-fn","name":"fn"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"app not found"}`))
+		}
+	})
+
+	fns, err := c.ListAppFunctions(context.Background(), "my-app")
+	r.NoError(err)
+	r.Equal([]json.RawMessage{json.RawMessage(`{"id":"my-app-fn","name":"fn"}`)}, fns)
+
+	_, err = c.ListAppFunctions(context.Background(), "missing")
+	r.ErrorIs(err, ErrNotFound)
+}
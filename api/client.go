@@ -0,0 +1,215 @@
+// Package api is a client for the Inngest REST API, used to inspect and manage
+// function runs:
+//
+//	c := api.New(api.Opts{})
+//
+//	run, err := c.GetRun(ctx, runID)
+//	if err == nil && run.Status == api.RunStatusCompleted {
+//		// ...
+//	}
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/inngest/inngestgo/internal/apiauth"
+	"github.com/inngest/inngestgo/pkg/env"
+)
+
+var (
+	// ErrNotFound is returned when the requested resource doesn't exist.
+	ErrNotFound = errors.New("not found")
+	// ErrUnauthorized is returned when the signing key is invalid.
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// Error is returned when the API responds with an error.  Error matches
+// ErrNotFound and ErrUnauthorized using errors.Is.
+type Error struct {
+	// StatusCode is the HTTP status code returned by the API.
+	StatusCode int
+	// Message is the error message returned by the API.
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("api request failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// Opts configures a Client.
+type Opts struct {
+	// SigningKey is the signing key used to authenticate requests.  If empty,
+	// this defaults to os.Getenv("INNGEST_SIGNING_KEY").
+	SigningKey string
+	// SigningKeyFallback is the fallback signing key, used once the signing key
+	// is rejected.  If empty, this defaults to
+	// os.Getenv("INNGEST_SIGNING_KEY_FALLBACK").
+	SigningKeyFallback string
+	// Env is the branch environment to use.  If empty, this defaults to
+	// os.Getenv("INNGEST_ENV").
+	Env string
+	// BaseURL is the URL of the Inngest API.  If empty, this defaults to
+	// os.Getenv("INNGEST_API_BASE_URL"), the Dev Server when INNGEST_DEV is set,
+	// or Inngest Cloud.
+	BaseURL string
+	// HTTPClient is the HTTP client used to make requests.  This defaults to a
+	// client with a 30 second timeout.
+	HTTPClient *http.Client
+}
+
+// Client makes requests to the Inngest REST API.
+type Client struct {
+	keys       *apiauth.Keys
+	env        string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// New returns a Client for the given options.
+func New(opts Opts) *Client {
+	if opts.SigningKey == "" {
+		opts.SigningKey = os.Getenv("INNGEST_SIGNING_KEY")
+	}
+	if opts.SigningKeyFallback == "" {
+		opts.SigningKeyFallback = os.Getenv("INNGEST_SIGNING_KEY_FALLBACK")
+	}
+	if opts.Env == "" {
+		opts.Env = os.Getenv("INNGEST_ENV")
+	}
+	if opts.BaseURL == "" {
+		opts.BaseURL = os.Getenv("INNGEST_API_BASE_URL")
+	}
+	if opts.BaseURL == "" {
+		opts.BaseURL = env.APIServerURL(nil)
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return &Client{
+		keys:       apiauth.NewKeys(opts.SigningKey, opts.SigningKeyFallback),
+		env:        opts.Env,
+		baseURL:    opts.BaseURL,
+		httpClient: opts.HTTPClient,
+	}
+}
+
+// PageOpts paginates list requests.
+type PageOpts struct {
+	// Limit is the maximum number of items to return.  If zero, the API's
+	// default is used.
+	Limit int
+	// Cursor is the cursor returned by the previous page, if any.
+	Cursor string
+}
+
+func (p PageOpts) query() url.Values {
+	q := url.Values{}
+	if p.Limit > 0 {
+		q.Set("limit", fmt.Sprintf("%d", p.Limit))
+	}
+	if p.Cursor != "" {
+		q.Set("cursor", p.Cursor)
+	}
+	return q
+}
+
+// Page is a single page of results.
+type Page[T any] struct {
+	// Data contains the page's items.
+	Data []T
+	// Cursor fetches the next page when passed as PageOpts.Cursor.  This is empty
+	// once there are no more pages.
+	Cursor string
+}
+
+// HasMore returns whether there are more pages.
+func (p Page[T]) HasMore() bool {
+	return p.Cursor != ""
+}
+
+// response is the envelope wrapping every API response.
+type response struct {
+	Data     json.RawMessage `json:"data"`
+	Error    string          `json:"error"`
+	Metadata struct {
+		Cursor string `json:"cursor"`
+	} `json:"metadata"`
+}
+
+// do makes a request to the API, unmarshalling the response's data into out if
+// non-nil and returning the response's pagination cursor.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, payload, out any) (string, error) {
+	var body []byte
+	if payload != nil {
+		var err error
+		if body, err = json.Marshal(payload); err != nil {
+			return "", fmt.Errorf("error marshalling request: %w", err)
+		}
+	}
+
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	resp, err := c.keys.Do(c.httpClient, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if c.env != "" {
+			req.Header.Set("X-Inngest-Env", c.env)
+		}
+		return req, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	byt, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading response: %w", err)
+	}
+
+	r := response{}
+	_ = json.Unmarshal(byt, &r)
+
+	if resp.StatusCode >= 400 {
+		msg := r.Error
+		if msg == "" {
+			msg = string(byt)
+		}
+		return "", &Error{StatusCode: resp.StatusCode, Message: msg}
+	}
+
+	if out != nil && len(r.Data) > 0 {
+		if err := json.Unmarshal(r.Data, out); err != nil {
+			return "", fmt.Errorf("error unmarshalling response: %w", err)
+		}
+	}
+	return r.Metadata.Cursor, nil
+}
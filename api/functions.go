@@ -0,0 +1,26 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Function is a synced function.
+type Function struct {
+	// ID is the function's internal UUID, as referenced by Run.FunctionID.
+	ID string `json:"id"`
+	// Slug is the function's fully qualified ID, prefixed with its app ID.
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+// GetFunction returns the function with the given fully qualified ID, or an
+// error matching ErrNotFound.
+func (c *Client) GetFunction(ctx context.Context, slug string) (*Function, error) {
+	fn := &Function{}
+	if _, err := c.do(ctx, http.MethodGet, "/v1/functions/"+url.PathEscape(slug), nil, nil, fn); err != nil {
+		return nil, err
+	}
+	return fn, nil
+}
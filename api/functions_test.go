@@ -0,0 +1,32 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFunction(t *testing.T) {
+	r := require.New(t)
+
+	c := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		r.Equal(http.MethodGet, req.Method)
+
+		switch req.URL.Path {
+		case "/v1/functions/my-app-fn":
+			_, _ = w.Write([]byte(`{"data":{"id":"0b8f9ad6-5a4e-4f5e-9d4c-2f1d5f0c7b1a","slug":"my-app-fn","name":"fn"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"function not found"}`))
+		}
+	})
+
+	fn, err := c.GetFunction(context.Background(), "my-app-fn")
+	r.NoError(err)
+	r.Equal(&Function{ID: "0b8f9ad6-5a4e-4f5e-9d4c-2f1d5f0c7b1a", Slug: "my-app-fn", Name: "fn"}, fn)
+
+	_, err = c.GetFunction(context.Background(), "missing")
+	r.ErrorIs(err, ErrNotFound)
+}
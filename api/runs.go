@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RunStatus is the status of a function run.
+type RunStatus string
+
+const (
+	RunStatusRunning   RunStatus = "Running"
+	RunStatusCompleted RunStatus = "Completed"
+	RunStatusFailed    RunStatus = "Failed"
+	RunStatusCancelled RunStatus = "Cancelled"
+)
+
+// Done returns whether the run has finished.
+func (s RunStatus) Done() bool {
+	return s == RunStatusCompleted || s == RunStatusFailed || s == RunStatusCancelled
+}
+
+// Run is a single function run.
+type Run struct {
+	RunID string `json:"run_id"`
+	// FunctionID is the internal UUID of the run's function, as returned by
+	// GetFunction.
+	FunctionID      string     `json:"function_id"`
+	FunctionVersion int        `json:"function_version"`
+	EnvironmentID   string     `json:"environment_id"`
+	EventID         string     `json:"event_id"`
+	Status          RunStatus  `json:"status"`
+	StartedAt       time.Time  `json:"run_started_at"`
+	EndedAt         *time.Time `json:"ended_at"`
+	// Output is the run's JSON-encoded output, once finished.  For failed runs,
+	// this contains the error.
+	Output json.RawMessage `json:"output"`
+}
+
+// RunOutput unmarshals the run's output into T.
+func RunOutput[T any](run *Run) (T, error) {
+	var out T
+	if len(run.Output) == 0 {
+		return out, nil
+	}
+	err := json.Unmarshal(run.Output, &out)
+	return out, err
+}
+
+// GetRun returns the run with the given ID, or an error matching ErrNotFound.
+func (c *Client) GetRun(ctx context.Context, runID string) (*Run, error) {
+	run := &Run{}
+	_, err := c.do(ctx, http.MethodGet, "/v1/runs/"+url.PathEscape(runID), nil, nil, run)
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// ListEventRuns returns the runs triggered by the given event.
+func (c *Client) ListEventRuns(ctx context.Context, eventID string, page PageOpts) (*Page[Run], error) {
+	runs := []Run{}
+	cursor, err := c.do(ctx, http.MethodGet, "/v1/events/"+url.PathEscape(eventID)+"/runs", page.query(), nil, &runs)
+	if err != nil {
+		return nil, err
+	}
+	return &Page[Run]{Data: runs, Cursor: cursor}, nil
+}
+
+// CancelRun cancels the run with the given ID.
+func (c *Client) CancelRun(ctx context.Context, runID string) error {
+	_, err := c.do(ctx, http.MethodDelete, "/v1/runs/"+url.PathEscape(runID), nil, nil, nil)
+	return err
+}
+
+// BulkCancelOpts selects the runs cancelled by BulkCancel.
+type BulkCancelOpts struct {
+	// AppID is the ID of the app containing the function.
+	AppID string `json:"app_id"`
+	// FunctionID is the ID of the function whose runs are cancelled.
+	FunctionID string `json:"function_id"`
+	// StartedAfter and StartedBefore restrict cancellation to runs started
+	// within the given time range.
+	StartedAfter  *time.Time `json:"started_after,omitempty"`
+	StartedBefore *time.Time `json:"started_before,omitempty"`
+	// If is an optional expression which runs' triggering events must match to
+	// be cancelled, eg. "event.data.user_id == 'u_123'".
+	If string `json:"if,omitempty"`
+}
+
+// Cancellation is a bulk cancellation created by BulkCancel.  Runs are cancelled
+// asynchronously.
+type Cancellation struct {
+	ID            string     `json:"id"`
+	AppID         string     `json:"app_id"`
+	FunctionID    string     `json:"function_id"`
+	StartedAfter  *time.Time `json:"started_after,omitempty"`
+	StartedBefore *time.Time `json:"started_before,omitempty"`
+	If            string     `json:"if,omitempty"`
+}
+
+// BulkCancel cancels all of a function's runs matching the given options.
+func (c *Client) BulkCancel(ctx context.Context, opts BulkCancelOpts) (*Cancellation, error) {
+	if opts.AppID == "" || opts.FunctionID == "" {
+		return nil, fmt.Errorf("app and function IDs are required")
+	}
+	result := &Cancellation{}
+	if _, err := c.do(ctx, http.MethodPost, "/v1/cancellations", nil, opts, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Replay is a replay of a run created by ReplayRun.
+type Replay struct {
+	// ID is the ID of the replay.
+	ID string `json:"id"`
+	// RunID is the ID of the new run, if already scheduled.
+	RunID string `json:"run_id,omitempty"`
+}
+
+// ReplayRun requests a replay of the run with the given ID, re-running the
+// function with the same triggering event.
+func (c *Client) ReplayRun(ctx context.Context, runID string) (*Replay, error) {
+	result := &Replay{}
+	if _, err := c.do(ctx, http.MethodPost, "/v1/runs/"+url.PathEscape(runID)+"/replay", nil, nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
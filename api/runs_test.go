@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return New(Opts{
+		SigningKey:         "signkey-test-primary",
+		SigningKeyFallback: "signkey-test-fallback",
+		Env:                "branch",
+		BaseURL:            srv.URL,
+		HTTPClient:         srv.Client(),
+	})
+}
+
+func TestGetRun(t *testing.T) {
+	r := require.New(t)
+
+	c := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		r.Equal(http.MethodGet, req.Method)
+		r.Equal("Bearer signkey-test-primary", req.Header.Get("Authorization"))
+		r.Equal("branch", req.Header.Get("X-Inngest-Env"))
+
+		switch req.URL.Path {
+		case "/v1/runs/run-1":
+			_, _ = w.Write([]byte(`{"data":{
+				"run_id": "run-1",
+				"function_id": "fn-1",
+				"status": "Completed",
+				"run_started_at": "2024-01-01T00:00:00Z",
+				"output": {"ok": true}
+			}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"run not found"}`))
+		}
+	})
+
+	run, err := c.GetRun(context.Background(), "run-1")
+	r.NoError(err)
+	r.Equal("run-1", run.RunID)
+	r.Equal(RunStatusCompleted, run.Status)
+	r.True(run.Status.Done())
+	r.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), run.StartedAt)
+
+	out, err := RunOutput[map[string]bool](run)
+	r.NoError(err)
+	r.Equal(map[string]bool{"ok": true}, out)
+
+	_, err = c.GetRun(context.Background(), "missing")
+	r.ErrorIs(err, ErrNotFound)
+	r.EqualError(err, "api request failed with status 404: run not found")
+}
+
+func TestListEventRuns(t *testing.T) {
+	r := require.New(t)
+
+	c := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		r.Equal("/v1/events/evt-1/runs", req.URL.Path)
+		r.Equal("2", req.URL.Query().Get("limit"))
+
+		if req.URL.Query().Get("cursor") == "" {
+			_, _ = w.Write([]byte(`{"data":[{"run_id":"run-1"},{"run_id":"run-2"}],"metadata":{"cursor":"next"}}`))
+			return
+		}
+		r.Equal("next", req.URL.Query().Get("cursor"))
+		_, _ = w.Write([]byte(`{"data":[{"run_id":"run-3"}],"metadata":{}}`))
+	})
+
+	page, err := c.ListEventRuns(context.Background(), "evt-1", PageOpts{Limit: 2})
+	r.NoError(err)
+	r.Len(page.Data, 2)
+	r.True(page.HasMore())
+
+	page, err = c.ListEventRuns(context.Background(), "evt-1", PageOpts{Limit: 2, Cursor: page.Cursor})
+	r.NoError(err)
+	r.Equal("run-3", page.Data[0].RunID)
+	r.False(page.HasMore())
+}
+
+func TestCancel(t *testing.T) {
+	r := require.New(t)
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	c := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/v1/runs/run-1":
+			r.Equal(http.MethodDelete, req.Method)
+			w.WriteHeader(http.StatusOK)
+		case "/v1/cancellations":
+			r.Equal(http.MethodPost, req.Method)
+			body := BulkCancelOpts{}
+			r.NoError(json.NewDecoder(req.Body).Decode(&body))
+			r.Equal("app", body.AppID)
+			r.Equal("fn", body.FunctionID)
+			r.Equal(after, *body.StartedAfter)
+			_, _ = w.Write([]byte(`{"data":{"id":"cancel-1","app_id":"app","function_id":"fn"}}`))
+		}
+	})
+
+	r.NoError(c.CancelRun(context.Background(), "run-1"))
+
+	cancellation, err := c.BulkCancel(context.Background(), BulkCancelOpts{
+		AppID:        "app",
+		FunctionID:   "fn",
+		StartedAfter: &after,
+	})
+	r.NoError(err)
+	r.Equal("cancel-1", cancellation.ID)
+
+	_, err = c.BulkCancel(context.Background(), BulkCancelOpts{})
+	r.Error(err)
+}
+
+func TestReplayRun(t *testing.T) {
+	r := require.New(t)
+
+	c := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		r.Equal(http.MethodPost, req.Method)
+		r.Equal("/v1/runs/run-1/replay", req.URL.Path)
+		_, _ = w.Write([]byte(`{"data":{"id":"replay-1"}}`))
+	})
+
+	replay, err := c.ReplayRun(context.Background(), "run-1")
+	r.NoError(err)
+	r.Equal("replay-1", replay.ID)
+}
+
+func TestSigningKeyFallback(t *testing.T) {
+	r := require.New(t)
+
+	auth := []string{}
+	c := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		auth = append(auth, req.Header.Get("Authorization"))
+		if req.Header.Get("Authorization") != "Bearer signkey-test-fallback" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"run_id":"run-1"}}`))
+	})
+
+	_, err := c.GetRun(context.Background(), "run-1")
+	r.NoError(err)
+	_, err = c.GetRun(context.Background(), "run-1")
+	r.NoError(err)
+
+	// The fallback key is used for all requests once the primary key fails.
+	r.Equal([]string{
+		"Bearer signkey-test-primary",
+		"Bearer signkey-test-fallback",
+		"Bearer signkey-test-fallback",
+	}, auth)
+}
@@ -0,0 +1,101 @@
+package inngestgo
+
+import (
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"slices"
+
+	"github.com/inngest/inngestgo/internal/fn"
+)
+
+// buildInfoVersion returns the app version from the binary's build info, or an
+// empty string if it has no version information.
+func buildInfoVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	return versionFromBuildInfo(info)
+}
+
+// versionFromBuildInfo returns the VCS revision, suffixed with "-dirty" if the
+// working tree was modified, or else the main module's version.
+func versionFromBuildInfo(info *debug.BuildInfo) string {
+	var revision, modified string
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value
+		}
+	}
+
+	if revision != "" {
+		if len(revision) > 12 {
+			revision = revision[:12]
+		}
+		if modified == "true" {
+			revision += "-dirty"
+		}
+		return revision
+	}
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return ""
+}
+
+// functionFingerprint returns a hash identifying a function's code and config.
+// This changes whenever the function's config, its location in the source or the
+// build's revision or dependencies change.  The function is located by its
+// symbol and line rather than its file, as file paths are absolute unless built
+// with -trimpath and so differ between build directories.
+func functionFingerprint(sf ServableFunction) (string, error) {
+	fingerprint := struct {
+		Config  *fn.SyncConfig `json:"config"`
+		Symbol  string         `json:"symbol"`
+		Line    int            `json:"line"`
+		Version string         `json:"version"`
+		Deps    []string       `json:"deps"`
+	}{
+		Config: fn.GetFnSyncConfig(sf),
+	}
+
+	if f := sf.Func(); f != nil {
+		if rf := runtime.FuncForPC(reflect.ValueOf(f).Pointer()); rf != nil {
+			fingerprint.Symbol = rf.Name()
+			_, fingerprint.Line = rf.FileLine(rf.Entry())
+		}
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		fingerprint.Version = versionFromBuildInfo(info)
+		for _, dep := range info.Deps {
+			fingerprint.Deps = append(fingerprint.Deps, dep.Path+"@"+dep.Version)
+		}
+		slices.Sort(fingerprint.Deps)
+	}
+
+	return configHash(fingerprint)
+}
+
+// functionsFingerprint returns a short hash of the fingerprints of all of the
+// given functions.
+func functionsFingerprint(fns []ServableFunction) (string, error) {
+	fingerprints := make([]string, len(fns))
+	for i, sf := range fns {
+		fingerprint, err := functionFingerprint(sf)
+		if err != nil {
+			return "", err
+		}
+		fingerprints[i] = fingerprint
+	}
+	slices.Sort(fingerprints)
+
+	hash, err := configHash(fingerprints)
+	if err != nil {
+		return "", err
+	}
+	return hash[:12], nil
+}
@@ -0,0 +1,95 @@
+package inngestgo
+
+import (
+	"context"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionFromBuildInfo(t *testing.T) {
+	revision := debug.BuildSetting{Key: "vcs.revision", Value: "0123456789abcdef0123"}
+
+	for name, test := range map[string]struct {
+		info     debug.BuildInfo
+		expected string
+	}{
+		"revision": {
+			info: debug.BuildInfo{
+				Main:     debug.Module{Version: "(devel)"},
+				Settings: []debug.BuildSetting{revision, {Key: "vcs.modified", Value: "false"}},
+			},
+			expected: "0123456789ab",
+		},
+		"modified": {
+			info: debug.BuildInfo{
+				Settings: []debug.BuildSetting{revision, {Key: "vcs.modified", Value: "true"}},
+			},
+			expected: "0123456789ab-dirty",
+		},
+		"module version": {
+			info:     debug.BuildInfo{Main: debug.Module{Version: "v1.2.3"}},
+			expected: "v1.2.3",
+		},
+		"no version": {
+			info: debug.BuildInfo{Main: debug.Module{Version: "(devel)"}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, test.expected, versionFromBuildInfo(&test.info))
+		})
+	}
+}
+
+func TestFingerprintFunctions(t *testing.T) {
+	r := require.New(t)
+
+	create := func(c Client, id string, retries int) ServableFunction {
+		sf, err := CreateFunction(
+			c,
+			FunctionOpts{ID: id, Retries: &retries},
+			EventTrigger("test/event", nil),
+			func(ctx context.Context, input Input[any]) (any, error) {
+				return nil, nil
+			},
+		)
+		r.NoError(err)
+		return sf
+	}
+
+	c, err := NewClient(ClientOpts{
+		AppID:                   "fingerprint",
+		AppVersion:              StrPtr("v1"),
+		AppVersionFromBuildInfo: true,
+		FingerprintFunctions:    true,
+	})
+	r.NoError(err)
+	h := c.(*apiClient).h
+
+	a := create(c, "a", 1)
+	first, err := functionFingerprint(a)
+	r.NoError(err)
+	second, err := functionFingerprint(a)
+	r.NoError(err)
+	r.Equal(first, second)
+
+	// Explicit versions take precedence over build info.
+	version := *h.GetAppVersion()
+	r.Regexp(`^v1\+[0-9a-f]{12}$`, version)
+
+	// Changing a function's config changes the version.
+	create(c, "b", 1)
+	changed, err := NewClient(ClientOpts{AppID: "fingerprint", AppVersion: StrPtr("v1"), FingerprintFunctions: true})
+	r.NoError(err)
+	create(changed, "a", 1)
+	create(changed, "b", 2)
+	r.NotEqual(*h.GetAppVersion(), *changed.(*apiClient).h.GetAppVersion())
+	r.NotEqual(version, *h.GetAppVersion())
+
+	// Without a version, the fingerprint is used.
+	unversioned, err := NewClient(ClientOpts{AppID: "fingerprint", FingerprintFunctions: true})
+	r.NoError(err)
+	create(unversioned, "a", 1)
+	r.Regexp(`^[0-9a-f]{12}$`, *unversioned.(*apiClient).h.GetAppVersion())
+}
@@ -9,11 +9,10 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"math"
-	mathrand "math/rand"
 	"net/http"
 	"net/url"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/inngest/inngestgo/internal/logger"
@@ -24,8 +23,6 @@ import (
 
 const (
 	defaultEndpoint = "https://inn.gs"
-	retryAttempts   = 5
-	retryBaseDelay  = 100 * time.Millisecond
 )
 
 // Client represents a client used to send events to Inngest.
@@ -34,7 +31,10 @@ type Client interface {
 
 	// Send sends the specific event to the ingest API.
 	Send(ctx context.Context, evt any) (string, error)
-	// Send sends a batch of events to the ingest API.
+	// SendMany sends a batch of events to the ingest API, returning IDs in the
+	// same order as the events.  Large batches are split across concurrent
+	// requests according to ClientOpts.SendBatch;  if any of these requests
+	// fail, the error is a *SendManyError listing the failed events.
 	SendMany(ctx context.Context, evt []any) ([]string, error)
 	// Options returns the cleint options set during initialization.
 	Options() ClientOpts
@@ -45,6 +45,51 @@ type Client interface {
 	SetURL(u *url.URL)
 }
 
+// The following interfaces are implemented by clients created with NewClient.
+// They're separate from Client so that other implementations of Client, such as
+// mocks, needn't implement them:
+//
+//	runID, err := client.(inngestgo.SignalSender).SendSignal(ctx, signal, data)
+
+// SignalSender is implemented by clients which send signals.
+type SignalSender interface {
+	// SendSignal sends a signal with the given data, resuming the run waiting
+	// for the signal via step.WaitForSignal and returning the run's ID.
+	SendSignal(ctx context.Context, signal string, data any) (string, error)
+}
+
+// Drainer is implemented by clients which drain invocations on shutdown.
+type Drainer interface {
+	// Drain stops the served handler from accepting new invocations and waits for
+	// running invocations to return control at their next step.  See
+	// DrainReport.
+	Drain(ctx context.Context) (DrainReport, error)
+}
+
+// HealthChecker is implemented by clients which report their health.
+type HealthChecker interface {
+	// HealthHandler returns an HTTP handler for liveness and readiness probes.
+	HealthHandler() http.Handler
+}
+
+// Syncer is implemented by clients which sync their functions with Inngest
+// directly from the process.
+type Syncer interface {
+	// SyncDiff compares the client's functions with those last synced to
+	// Inngest.
+	SyncDiff(ctx context.Context) (SyncDiff, error)
+	// Sync syncs the app's functions with Inngest from the process, using the
+	// configured serve URL unless overridden.
+	Sync(ctx context.Context, opts SyncOpts) error
+}
+
+var (
+	_ SignalSender  = &apiClient{}
+	_ Drainer       = &apiClient{}
+	_ HealthChecker = &apiClient{}
+	_ Syncer        = &apiClient{}
+)
+
 type ClientOpts struct {
 	AppID string
 
@@ -54,6 +99,12 @@ type ClientOpts struct {
 
 	// HTTPClient is the HTTP client used to send events.
 	HTTPClient *http.Client
+	// SendRetry configures how sending events is retried.  If nil, this uses
+	// DefaultSendRetryPolicy.
+	SendRetry *SendRetryPolicy
+	// SendBatch configures how SendMany splits events across requests.  If nil,
+	// this uses DefaultSendBatchOpts.
+	SendBatch *SendBatchOpts
 	// EventKey is your Inngest event key for sending events.  This defaults to the
 	// `INNGEST_EVENT_KEY` environment variable if nil.
 	EventKey *string
@@ -81,6 +132,18 @@ type ClientOpts struct {
 	// defaults to os.Getenv("INNGEST_SIGNING_KEY_FALLBACK").
 	SigningKeyFallback *string
 
+	// SigningKeyProvider, if set, supplies the signing key and fallback signing
+	// key whenever they're used, overriding SigningKey and SigningKeyFallback.
+	// This allows keys to be rotated without restarting, eg. using
+	// signingkey.NewFile or signingkey.NewCache.
+	SigningKeyProvider SigningKeyProvider
+
+	// ReplayStore, if set, rejects signed requests from Inngest whose signature
+	// has already been used, protecting against captured requests being
+	// replayed.  Use replay.NewLRU for a single replica, or a shared store when
+	// running multiple replicas.
+	ReplayStore ReplayStore
+
 	// APIOrigin is the specified host to be used to make API calls
 	APIBaseURL *string
 
@@ -97,7 +160,19 @@ type ClientOpts struct {
 	// whenever code within one of your Inngest function or any dependency thereof changes.
 	AppVersion *string
 
-	// MaxBodySize is the max body size to read for incoming invoke requests
+	// AppVersionFromBuildInfo derives the app version from the binary's build
+	// info if AppVersion is nil: the VCS revision, suffixed with "-dirty" if the
+	// working tree was modified, or else the main module's version.
+	AppVersionFromBuildInfo bool
+
+	// FingerprintFunctions appends a fingerprint of the app's functions to the
+	// app version.  Each function's fingerprint changes with its config, its
+	// location in the source, and the build's revision and dependencies, so the
+	// app version changes even for builds without VCS information.
+	FingerprintFunctions bool
+
+	// MaxBodySize is the max body size to read for incoming invoke requests.
+	// Compressed bodies are limited both before and after decompression.
 	MaxBodySize int
 
 	// URL that the function is served at.  If not supplied this is taken from
@@ -108,6 +183,14 @@ type ClientOpts struct {
 	// differs from true streaming in that we don't support server-sent events.
 	UseStreaming bool
 
+	// UseServerSentEvents streams invocations as server-sent events: a
+	// "heartbeat" event keeps the connection alive, a "step" event is sent as
+	// each step op is reported, and a final "result" event contains the
+	// StreamResponse.  Events are only sent to requests which accept
+	// "text/event-stream"; other requests receive the usual JSON response.  This
+	// takes precedence over UseStreaming.
+	UseServerSentEvents bool
+
 	// Dev is whether to use the Dev Server.
 	Dev *bool
 
@@ -154,20 +237,36 @@ func NewClient(opts ClientOpts) (Client, error) {
 	return c, nil
 }
 
+// appVersion returns the configured app version, falling back to the build info
+// if enabled.
+func appVersion(opts ClientOpts) *string {
+	if opts.AppVersion != nil || !opts.AppVersionFromBuildInfo {
+		return opts.AppVersion
+	}
+	if v := buildInfoVersion(); v != "" {
+		return &v
+	}
+	return nil
+}
+
 func clientOptsToHandlerOpts(opts ClientOpts) handlerOpts {
 	return handlerOpts{
-		Logger:             opts.Logger,
-		SigningKey:         opts.SigningKey,
-		SigningKeyFallback: opts.SigningKeyFallback,
-		APIBaseURL:         opts.APIBaseURL,
-		EventAPIBaseURL:    opts.EventAPIBaseURL,
-		Env:                opts.Env,
-		RegisterURL:        opts.RegisterURL,
-		AppVersion:         opts.AppVersion,
-		MaxBodySize:        opts.MaxBodySize,
-		URL:                opts.URL,
-		UseStreaming:       opts.UseStreaming,
-		Dev:                opts.Dev,
+		Logger:               opts.Logger,
+		SigningKey:           opts.SigningKey,
+		SigningKeyFallback:   opts.SigningKeyFallback,
+		SigningKeyProvider:   opts.SigningKeyProvider,
+		ReplayStore:          opts.ReplayStore,
+		APIBaseURL:           opts.APIBaseURL,
+		EventAPIBaseURL:      opts.EventAPIBaseURL,
+		Env:                  opts.Env,
+		RegisterURL:          opts.RegisterURL,
+		AppVersion:           appVersion(opts),
+		FingerprintFunctions: opts.FingerprintFunctions,
+		MaxBodySize:          opts.MaxBodySize,
+		URL:                  opts.URL,
+		UseStreaming:         opts.UseStreaming,
+		UseServerSentEvents:  opts.UseServerSentEvents,
+		Dev:                  opts.Dev,
 	}
 }
 
@@ -231,6 +330,37 @@ type ServeOpts struct {
 	// EnableUnauthedSync allows unsigned out-of-band sync requests in cloud mode.
 	// If nil, this defaults to INNGEST_ENABLE_UNAUTHED_SYNC.
 	EnableUnauthedSync *bool
+
+	// MaxConcurrentInvocations limits the number of function invocations served
+	// concurrently.  Excess invocations are rejected with a 503 status and a
+	// Retry-After header, so that Inngest retries them later.  If zero,
+	// invocations are unlimited.
+	MaxConcurrentInvocations int
+
+	// MaxHeapBytes rejects function invocations while the Go heap exceeds the
+	// given size, in the same way as MaxConcurrentInvocations.  If zero, memory
+	// isn't checked.
+	MaxHeapBytes uint64
+
+	// OverloadRetryAfter is the delay requested from Inngest when rejecting
+	// invocations, defaulting to 5 seconds.
+	OverloadRetryAfter time.Duration
+
+	// DetailedInspection includes each function's synced config, a hash of the
+	// config and invocation stats in responses to signed GET requests.  This
+	// helps to check which code a process is running, eg. when debugging syncs.
+	DetailedInspection bool
+
+	// SyncOnStart syncs the app's functions with Inngest in the background once
+	// the app is served, using the configured serve URL and retrying failures.
+	// This replaces deploy hooks which sync by sending a PUT request to the serve
+	// URL.  See Client.Sync.
+	//
+	// The sync starts on the first request to the serve handler or the client's
+	// HealthHandler, eg. a readiness probe, so that functions created after
+	// calling Serve are included.  To sync at a specific point instead, call
+	// Client.Sync once every function has been created.
+	SyncOnStart bool
 }
 
 func (a apiClient) Serve() http.Handler {
@@ -243,9 +373,20 @@ func (a apiClient) ServeWithOpts(opts ServeOpts) http.Handler {
 	if opts.EnableUnauthedSync != nil {
 		a.h.EnableUnauthedSync = opts.EnableUnauthedSync
 	}
+	a.h.MaxConcurrentInvocations = opts.MaxConcurrentInvocations
+	a.h.MaxHeapBytes = opts.MaxHeapBytes
+	a.h.OverloadRetryAfter = opts.OverloadRetryAfter
+	a.h.DetailedInspection = opts.DetailedInspection
+	if opts.SyncOnStart {
+		a.h.syncOnStart(false)
+	}
 	return a.h
 }
 
+func (a apiClient) Drain(ctx context.Context) (DrainReport, error) {
+	return a.h.Drain(ctx)
+}
+
 func (a *apiClient) SetOptions(opts ClientOpts) error {
 	err := opts.validate()
 	if err != nil {
@@ -289,72 +430,128 @@ func (a apiClient) SendMany(ctx context.Context, e []any) (ids []string, err err
 		}
 	}
 
+	encoded := make([]json.RawMessage, len(e))
+	for i, evt := range e {
+		if encoded[i], err = json.Marshal(evt); err != nil {
+			return nil, fmt.Errorf("error marshalling event to json: %w", err)
+		}
+	}
+
+	opts := DefaultSendBatchOpts
+	if a.SendBatch != nil {
+		opts = a.SendBatch.withDefaults()
+	}
+
+	chunks := chunkEvents(encoded, opts.MaxEvents, opts.MaxBytes)
+	if len(chunks) <= 1 {
+		return a.sendWithRetry(ctx, encoded)
+	}
+	return a.sendChunks(ctx, encoded, chunks, opts.Concurrency)
+}
+
+// sendChunks sends each chunk of events concurrently, returning IDs in the same
+// order as the events.
+func (a apiClient) sendChunks(ctx context.Context, events []json.RawMessage, chunks []sendChunk, concurrency int) ([]string, error) {
+	var (
+		ids    = make([]string, len(events))
+		errs   = make([]error, len(chunks))
+		sem    = make(chan struct{}, concurrency)
+		wg     sync.WaitGroup
+		failed []FailedChunk
+	)
+
+	for n, c := range chunks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			chunkIDs, err := a.sendWithRetry(ctx, events[c.start:c.end])
+			if err != nil {
+				errs[n] = err
+				return
+			}
+			copy(ids[c.start:c.end], chunkIDs)
+		}()
+	}
+	wg.Wait()
+
+	for n, err := range errs {
+		if err != nil {
+			failed = append(failed, FailedChunk{Start: chunks[n].start, End: chunks[n].end, Err: err})
+		}
+	}
+	if len(failed) > 0 {
+		return ids, &SendManyError{Total: len(events), Failed: failed}
+	}
+	return ids, nil
+}
+
+// sendWithRetry sends the events within a single request, retrying according to
+// the client's retry policy.
+func (a apiClient) sendWithRetry(ctx context.Context, events []json.RawMessage) ([]string, error) {
 	seed, err := seed()
 	if err != nil {
 		return nil, err
 	}
 
-	byt, err := json.Marshal(e)
+	byt, err := json.Marshal(events)
 	if err != nil {
 		return nil, fmt.Errorf("error marshalling event to json: %w", err)
 	}
 
-	var (
-		resp    *http.Response
-		respErr error
-	)
-	for attempt := 0; attempt < retryAttempts; attempt++ {
-		req, err := http.NewRequest(
-			http.MethodPost,
-			fmt.Sprintf("%s/e/%s", a.eventAPIBaseURL(), a.GetEventKey()),
-			bytes.NewBuffer(byt),
-		)
-		if err != nil {
-			return nil, fmt.Errorf("error creating event request: %w", err)
-		}
-		SetBasicRequestHeaders(req)
-		req.Header.Set(HeaderKeyEventIDSeed, seed)
+	policy := DefaultSendRetryPolicy
+	if a.SendRetry != nil {
+		policy = a.SendRetry.withDefaults()
+	}
 
-		if a.GetEnv() != "" {
-			req.Header.Add(HeaderKeyEnv, a.GetEnv())
+	for attempt := 0; ; attempt++ {
+		ids, retryAfter, err := a.sendEvents(ctx, byt, seed)
+		if err == nil {
+			return ids, nil
 		}
-		resp, respErr = a.HTTPClient.Do(req)
 
-		// Don't retry if the request was successful or if there was a 4xx
-		// status code. We don't want to retry on 4xx because the request is
+		// Don't retry 4xx errors other than rate limiting, as the request is
 		// malformed and retrying will just fail again.
-		if respErr == nil && resp.StatusCode < 500 {
-			break
+		var sendErr *SendError
+		if errors.As(err, &sendErr) && !sendErr.Retryable() {
+			return nil, err
 		}
-
-		if respErr != nil && resp != nil && resp.Body != nil {
-			// Close since we're gonna retry and we don't want to leak resources.
-			_ = resp.Body.Close()
+		if ctx.Err() != nil || attempt+1 >= policy.MaxAttempts {
+			return nil, err
 		}
 
-		// Jitter between 0 and the base delay.
-		jitter := time.Duration(mathrand.Float64() * float64(retryBaseDelay))
-
-		// Exponential backoff with jitter.
-		delay := retryBaseDelay*time.Duration(math.Pow(2, float64(attempt))) + jitter
-
-		time.Sleep(delay)
+		if serr := sleep(ctx, policy.delay(attempt, retryAfter)); serr != nil {
+			return nil, err
+		}
 	}
+}
 
-	if respErr != nil {
-		if resp != nil {
-			_ = resp.Body.Close()
-		}
-		return nil, respErr
+// sendEvents makes a single request to the event API, returning any delay
+// requested via the Retry-After header.
+func (a apiClient) sendEvents(ctx context.Context, byt []byte, seed string) ([]string, time.Duration, error) {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("%s/e/%s", a.eventAPIBaseURL(), a.GetEventKey()),
+		bytes.NewBuffer(byt),
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error creating event request: %w", err)
 	}
+	SetBasicRequestHeaders(req)
+	req.Header.Set(HeaderKeyEventIDSeed, seed)
 
-	if resp == nil {
-		// NOTE: We'd expect respErr to be non-nil and caught above in every case.  It's typically
-		// impossible that the response is nil AND respErr is nil.  For safety, though, we must check
-		// both cases.
-		return nil, fmt.Errorf("unable to send events:  no http response")
+	if a.GetEnv() != "" {
+		req.Header.Add(HeaderKeyEnv, a.GetEnv())
 	}
 
+	resp, err := a.HTTPClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
 	// There is no body to read;  the ingest API responds with status codes representing
 	// each error.  We don't necessarily care about the error behind this close.
 	defer func() {
@@ -364,7 +561,9 @@ func (a apiClient) SendMany(ctx context.Context, e []any) (ids []string, err err
 	var respBody eventAPIResponse
 	_ = json.NewDecoder(resp.Body).Decode(&respBody)
 
-	return handleEventResponse(respBody, resp.StatusCode)
+	retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	ids, err := handleEventResponse(respBody, resp.StatusCode, retryAfter)
+	return ids, retryAfter, err
 }
 
 func (a apiClient) eventAPIBaseURL() string {
@@ -389,27 +588,17 @@ func (a apiClient) eventAPIBaseURL() string {
 	return defaultEventAPIOrigin
 }
 
-func handleEventResponse(r eventAPIResponse, status int) ([]string, error) {
-	msg := "unknown error"
-	if r.Error != "" {
-		msg = r.Error
-	}
-
+func handleEventResponse(r eventAPIResponse, status int, retryAfter time.Duration) ([]string, error) {
 	switch status {
 	case 200, 201:
 		return r.IDs, nil
-	case 400:
-		// E.g. the event is invalid.
-		return nil, fmt.Errorf("bad request: %s", msg)
-	case 401:
-		// E.g. the event key is invalid.
-		return nil, fmt.Errorf("unauthorized: %s", msg)
-	case 403:
-		// E.g. the ingest key has an IP or event type allow/denylist.
-		return nil, fmt.Errorf("forbidden: %s", msg)
-	}
-
-	return nil, fmt.Errorf("unknown status code sending event: %d", status)
+	}
+
+	return nil, &SendError{
+		StatusCode: status,
+		Message:    r.Error,
+		RetryAfter: retryAfter,
+	}
 }
 
 func seed() (string, error) {
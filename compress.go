@@ -0,0 +1,95 @@
+package inngestgo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// compressionMinSize is the smallest response body which is compressed.  Smaller
+// bodies aren't worth the overhead.
+const compressionMinSize = 1024
+
+// readBody reads a request body, decompressing it according to its
+// Content-Encoding.  Only gzip is supported, as zstd would require a third-party
+// dependency.  max limits both the compressed body and the decompressed body,
+// guarding against decompression bombs.
+func readBody(w http.ResponseWriter, r *http.Request, max int) ([]byte, error) {
+	body := http.MaxBytesReader(w, r.Body, int64(max))
+
+	switch encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding {
+	case "", "identity":
+		return io.ReadAll(body)
+	case "gzip":
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("error decompressing request body: %w", err)
+		}
+		defer func() {
+			_ = gz.Close()
+		}()
+
+		byt, err := io.ReadAll(io.LimitReader(gz, int64(max)+1))
+		if err != nil {
+			return nil, fmt.Errorf("error decompressing request body: %w", err)
+		}
+		if len(byt) > max {
+			return nil, fmt.Errorf("request body exceeds %d bytes once decompressed", max)
+		}
+		return byt, nil
+	default:
+		return nil, fmt.Errorf("unsupported content encoding: %s", encoding)
+	}
+}
+
+// acceptsGzip returns whether the request accepts gzip-encoded responses.
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+
+		// gzip isn't acceptable if its quality value is zero.
+		for _, param := range strings.Split(params, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if key != "q" {
+				continue
+			}
+			if q, err := strconv.ParseFloat(value, 64); err == nil && q == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// writeJSON writes a JSON response with the given status, compressing the body
+// with gzip if it's large and the request accepts it.
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) error {
+	buf := &bytes.Buffer{}
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
+		return err
+	}
+
+	w.Header().Add("Vary", "Accept-Encoding")
+	if buf.Len() < compressionMinSize || !acceptsGzip(r) {
+		w.WriteHeader(status)
+		_, err := w.Write(buf.Bytes())
+		return err
+	}
+
+	w.Header().Set("Content-Encoding", "gzip")
+	w.WriteHeader(status)
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return gz.Close()
+}
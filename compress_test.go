@@ -0,0 +1,160 @@
+package inngestgo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func gzipBytes(t *testing.T, byt []byte) []byte {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	_, err := gz.Write(byt)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestCompression(t *testing.T) {
+	setEnvVars(t)
+
+	c, err := NewClient(ClientOpts{AppID: "compression", MaxBodySize: 4096})
+	require.NoError(t, err)
+
+	large := strings.Repeat("a", 2*compressionMinSize)
+	fn, err := CreateFunction(
+		c,
+		FunctionOpts{ID: "fn"},
+		EventTrigger("test/event.a", nil),
+		func(ctx context.Context, input Input[EventAData]) (any, error) {
+			if input.Event.Data.Foo == "large" {
+				return large, nil
+			}
+			return input.Event.Data.Foo, nil
+		},
+	)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(c.Serve())
+	defer server.Close()
+
+	q := url.Values{}
+	q.Add("fnId", fn.FullyQualifiedID())
+	urlStr := fmt.Sprintf("%s?%s", server.URL, q.Encode())
+
+	// Disable the transport's transparent decompression to inspect responses.
+	httpClient := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+
+	post := func(t *testing.T, body []byte, header http.Header) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, urlStr, nil)
+		require.NoError(t, err)
+		req.Header = header
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		resp, err := httpClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = resp.Body.Close()
+		})
+		return resp
+	}
+
+	signed := func(t *testing.T, foo string) ([]byte, http.Header) {
+		body := marshalRequest(t, createRequest(t, EventA{
+			Name: "test/event.a",
+			Data: EventAData{Foo: foo},
+		}))
+		sig, err := Sign(context.Background(), time.Now(), []byte(testKey), body)
+		require.NoError(t, err)
+		return body, http.Header{HeaderKeySignature: []string{sig}}
+	}
+
+	t.Run("compressed request", func(t *testing.T) {
+		r := require.New(t)
+		body, header := signed(t, "hello")
+		header.Set("Content-Encoding", "gzip")
+
+		resp := post(t, gzipBytes(t, body), header)
+		r.Equal(http.StatusOK, resp.StatusCode)
+
+		var out string
+		r.NoError(json.NewDecoder(resp.Body).Decode(&out))
+		r.Equal("hello", out)
+	})
+
+	t.Run("decompressed request too large", func(t *testing.T) {
+		body, header := signed(t, strings.Repeat("b", 8192))
+		header.Set("Content-Encoding", "gzip")
+
+		compressed := gzipBytes(t, body)
+		require.Less(t, len(compressed), 4096)
+		resp := post(t, compressed, header)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("unsupported encoding", func(t *testing.T) {
+		body, header := signed(t, "hello")
+		header.Set("Content-Encoding", "br")
+
+		resp := post(t, body, header)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("compressed response", func(t *testing.T) {
+		r := require.New(t)
+		body, header := signed(t, "large")
+		header.Set("Accept-Encoding", "gzip")
+
+		resp := post(t, body, header)
+		r.Equal(http.StatusOK, resp.StatusCode)
+		r.Equal("gzip", resp.Header.Get("Content-Encoding"))
+
+		gz, err := gzip.NewReader(resp.Body)
+		r.NoError(err)
+		var out string
+		r.NoError(json.NewDecoder(gz).Decode(&out))
+		r.Equal(large, out)
+	})
+
+	t.Run("uncompressed response", func(t *testing.T) {
+		r := require.New(t)
+
+		// Small responses aren't compressed.
+		body, header := signed(t, "hello")
+		header.Set("Accept-Encoding", "gzip")
+		resp := post(t, body, header)
+		r.Empty(resp.Header.Get("Content-Encoding"))
+
+		// Responses aren't compressed unless accepted.
+		body, header = signed(t, "large")
+		resp = post(t, body, header)
+		r.Empty(resp.Header.Get("Content-Encoding"))
+	})
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	for header, expected := range map[string]bool{
+		"":                     false,
+		"gzip":                 true,
+		"br, GZIP":             true,
+		"deflate, gzip;q=0.5":  true,
+		"gzip;q=0":             false,
+		"gzip; q=0.0, deflate": false,
+		"identity":             false,
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.Header.Set("Accept-Encoding", header)
+		require.Equal(t, expected, acceptsGzip(r), header)
+	}
+}
@@ -81,35 +81,31 @@ func Connect(ctx context.Context, opts ConnectOpts) (connect.WorkerConnection, e
 		return nil, fmt.Errorf("invalid handler passed")
 	}
 
-	var hashedKey []byte
-	var hashedFallbackKey []byte
-	signingKey := defaultClient.h.GetSigningKey()
-	if signingKey == "" {
-		if !defaultClient.h.isDev() {
-			// Signing key is only required in cloud mode.
-			return nil, fmt.Errorf("signing key is required")
-		}
-	} else {
-		var err error
-		hashedKey, err = hashedSigningKey([]byte(signingKey))
-		if err != nil {
-			return nil, fmt.Errorf("failed to hash signing key: %w", err)
-		}
+	hashedKey, hashedFallbackKey, err := defaultClient.hashedSigningKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if hashedKey == nil && !defaultClient.h.isDev() {
+		// Signing key is only required in cloud mode.
+		return nil, fmt.Errorf("signing key is required")
+	}
 
-		if fallbackKey := defaultClient.h.GetSigningKeyFallback(); fallbackKey != "" {
-			hashedFallbackKey, err = hashedSigningKey([]byte(fallbackKey))
-			if err != nil {
-				return nil, fmt.Errorf("failed to hash fallback signing key: %w", err)
-			}
+	var signingKeys func(ctx context.Context) ([]byte, []byte, error)
+	if defaultClient.h.SigningKeyProvider != nil {
+		// Reload keys before connecting so that rotated keys are used when
+		// reconnecting.
+		signingKeys = func(ctx context.Context) ([]byte, []byte, error) {
+			return defaultClient.hashedSigningKeys(ctx)
 		}
 	}
 
-	return connect.Connect(ctx, connect.Opts{
+	conn, err := connect.Connect(ctx, connect.Opts{
 		Apps:                            apps,
 		Env:                             defaultClient.Env,
 		Capabilities:                    capabilities,
 		HashedSigningKey:                hashedKey,
 		HashedSigningKeyFallback:        hashedFallbackKey,
+		SigningKeys:                     signingKeys,
 		MaxWorkerConcurrency:            opts.MaxWorkerConcurrency,
 		MessageReadLimit:                opts.MessageReadLimit,
 		MissedGatewayHeartbeatTolerance: opts.MissedGatewayHeartbeatTolerance,
@@ -121,6 +117,14 @@ func Connect(ctx context.Context, opts ConnectOpts) (connect.WorkerConnection, e
 		SDKLanguage:                     SDKLanguage,
 		RewriteGatewayEndpoint:          opts.RewriteGatewayEndpoint,
 	}, invokers, defaultClient.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range opts.Apps {
+		a.(*apiClient).h.setConnection(conn)
+	}
+	return conn, nil
 }
 
 func (h *handler) getServableFunctionBySlug(slug string) ServableFunction {
@@ -157,15 +161,41 @@ func (h *handler) InvokeFunction(ctx context.Context, slug string, stepId *strin
 	mw := middleware.New().Add(cImpl.Middleware...)
 
 	// Invoke function, always complete regardless of
+	keys := h.signingKeys(ctx)
 	resp, ops, err := invoke(
 		context.Background(),
 		h.client,
 		mw,
 		fn,
-		h.GetSigningKey(),
-		h.GetSigningKeyFallback(),
+		keys.Current,
+		keys.Fallback,
 		&request,
 		stepId,
 	)
+	h.recordInvocation(fn.FullyQualifiedID(), ops, err)
 	return resp, ops, err
 }
+
+// hashedSigningKeys returns the client's hashed signing key and fallback key, or
+// nil if they're not set.
+func (a apiClient) hashedSigningKeys(ctx context.Context) ([]byte, []byte, error) {
+	keys := a.h.signingKeys(ctx)
+	signingKey := keys.Current
+	if signingKey == "" {
+		return nil, nil, nil
+	}
+
+	hashedKey, err := hashedSigningKey([]byte(signingKey))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to hash signing key: %w", err)
+	}
+
+	var hashedFallbackKey []byte
+	if fallbackKey := keys.Fallback; fallbackKey != "" {
+		hashedFallbackKey, err = hashedSigningKey([]byte(fallbackKey))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to hash fallback signing key: %w", err)
+		}
+	}
+	return hashedKey, hashedFallbackKey, nil
+}
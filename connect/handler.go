@@ -1,6 +1,7 @@
 package connect
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -118,6 +119,11 @@ type Opts struct {
 	HashedSigningKey         []byte
 	HashedSigningKeyFallback []byte
 
+	// SigningKeys optionally reloads the hashed signing keys before every
+	// connection attempt, allowing keys to be rotated without restarting the
+	// worker.
+	SigningKeys func(ctx context.Context) (hashed []byte, hashedFallback []byte, err error)
+
 	MaxWorkerConcurrency *int64
 
 	// MessageReadLimit sets the max number of bytes to read for a single WebSocket message.
@@ -189,6 +195,29 @@ type authContext struct {
 	fallback         bool
 }
 
+// refreshSigningKeys reloads the signing keys, if configured.  If the signing key
+// changed, new connections authenticate using the new key rather than any
+// fallback key, and this returns true.
+func (h *connectHandler) refreshSigningKeys(ctx context.Context) bool {
+	if h.opts.SigningKeys == nil {
+		return false
+	}
+
+	key, fallback, err := h.opts.SigningKeys(ctx)
+	if err != nil {
+		h.logger.Error("could not reload signing keys", "err", err)
+		return false
+	}
+
+	changed := !bytes.Equal(key, h.opts.HashedSigningKey)
+	h.opts.HashedSigningKey = key
+	h.opts.HashedSigningKeyFallback = fallback
+	if changed && h.auth.hashedSigningKey != nil {
+		h.auth = authContext{hashedSigningKey: key}
+	}
+	return changed
+}
+
 func (h *connectHandler) Connect(ctx context.Context) (WorkerConnection, error) {
 	// While the worker is starting, it can be canceled using the passed context
 	startCtx, cancelStart := context.WithTimeout(ctx, time.Second*30)
@@ -196,6 +225,7 @@ func (h *connectHandler) Connect(ctx context.Context) (WorkerConnection, error)
 
 	l := h.logger
 
+	h.refreshSigningKeys(ctx)
 	signingKey := h.opts.HashedSigningKey
 	if len(signingKey) == 0 && !h.opts.IsDev {
 		return nil, fmt.Errorf("hashed signing key is required")
@@ -305,7 +335,10 @@ func (h *connectHandler) Connect(ctx context.Context) (WorkerConnection, error)
 						}
 					}
 
-					if errors.Is(msg.err, ErrUnauthenticated) {
+					if errors.Is(msg.err, ErrUnauthenticated) && h.refreshSigningKeys(ctx) {
+						// The signing key was rotated, so retry with the new key.
+						l.Info("signing key changed, reconnecting with new key")
+					} else if errors.Is(msg.err, ErrUnauthenticated) {
 						if h.auth.fallback {
 							err := fmt.Errorf("failed to authenticate with fallback key, exiting")
 							h.setState(ConnectionStateClosed, "fallback authentication failed", "err", msg.err)
@@ -413,6 +446,8 @@ func (h *connectHandler) Connect(ctx context.Context) (WorkerConnection, error)
 
 			attempts++
 
+			h.refreshSigningKeys(ctx)
+
 			connectCtx := ctx
 			if isInitialConnection {
 				connectCtx = startCtx
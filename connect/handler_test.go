@@ -1441,3 +1441,63 @@ func (c *captureInvoker) InvokeFunction(
 	c.request = request
 	return map[string]bool{"ok": true}, nil, nil
 }
+
+func TestConnectReloadsRotatedSigningKey(t *testing.T) {
+	r := require.New(t)
+
+	connectCtx, cancelConnect := context.WithCancel(context.Background())
+	defer cancelConnect()
+
+	var rotated atomic.Bool
+	apiClient := newWorkerApiClient("", nil)
+	logger := slog.New(slog.DiscardHandler)
+	h := &connectHandler{
+		opts: Opts{
+			HashedSigningKey: []byte("key-a"),
+			SigningKeys: func(context.Context) ([]byte, []byte, error) {
+				if rotated.Load() {
+					return []byte("key-b"), []byte("key-a"), nil
+				}
+				return []byte("key-a"), nil, nil
+			},
+		},
+		logger:                 logger,
+		notifyConnectDoneChan:  make(chan connectReport),
+		notifyConnectedChan:    make(chan struct{}),
+		initiateConnectionChan: make(chan struct{}, 1),
+		notifyFlushChan:        make(chan struct{}, 1),
+		apiClient:              apiClient,
+		messageBuffer:          newMessageBuffer(apiClient, logger),
+		state:                  ConnectionStateConnecting,
+		reconnectBackoff: func(int) time.Duration {
+			return 0
+		},
+	}
+
+	keys := make(chan string, 2)
+	var startCalls atomic.Int32
+	h.startConnection = func(_ context.Context, data connectionEstablishData, _ ...connectOpt) {
+		keys <- string(data.hashedSigningKey)
+		if startCalls.Add(1) == 1 {
+			h.notifyConnectedChan <- struct{}{}
+		}
+	}
+	h.workerCtx, h.cancelWorkerCtx = context.WithCancel(context.Background())
+	defer h.cancelWorkerCtx()
+
+	_, err := h.Connect(connectCtx)
+	r.NoError(err)
+	r.Equal("key-a", <-keys)
+
+	// The key is rotated and the old key is revoked, so the next connection
+	// uses the new key rather than the fallback.
+	rotated.Store(true)
+	h.notifyConnectDoneChan <- connectReport{
+		reconnect: true,
+		err:       newReconnectErr(ErrUnauthenticated),
+	}
+	r.Equal("key-b", <-keys)
+
+	cancelConnect()
+	r.NoError(h.Close())
+}
@@ -0,0 +1,181 @@
+package inngestgo
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/inngest/inngestgo/internal/sdkrequest"
+)
+
+// DrainReport describes the invocations served while draining a handler.
+type DrainReport struct {
+	// Completed is the number of invocations which finished while draining.
+	Completed int
+	// Abandoned lists the invocations still running when draining ended.  These
+	// are retried by Inngest once their requests fail.
+	Abandoned []AbandonedInvocation
+}
+
+// AbandonedInvocation is an invocation which was still running when draining
+// ended.
+type AbandonedInvocation struct {
+	FunctionID string
+	RunID      string
+	RequestID  string
+	// Started is when the invocation started.
+	Started time.Time
+}
+
+// invocations tracks the invocations being served, allowing them to be limited
+// and drained.
+type invocations struct {
+	l        sync.Mutex
+	running  map[*invocation]struct{}
+	draining bool
+	// idle is closed once no invocations are running while draining.
+	idle chan struct{}
+}
+
+// invocation is a single function invocation being served.
+type invocation struct {
+	fnID      string
+	requestID string
+	started   time.Time
+
+	l        sync.Mutex
+	runID    string
+	mgr      sdkrequest.InvocationManager
+	draining bool
+}
+
+type invocationCtxKeyType struct{}
+
+var invocationCtxKey = invocationCtxKeyType{}
+
+// start records the invocation's manager once the function is called.  If the
+// handler is already draining, the invocation is drained immediately.
+func (i *invocation) start(ctx context.Context, runID string, mgr sdkrequest.InvocationManager) {
+	i.l.Lock()
+	defer i.l.Unlock()
+
+	i.runID = runID
+	i.mgr = mgr
+	if i.draining {
+		mgr.Drain(ctx)
+	}
+}
+
+// drain returns control to the executor at the invocation's next step, flushing
+// any buffered checkpoints.
+func (i *invocation) drain(ctx context.Context) {
+	i.l.Lock()
+	defer i.l.Unlock()
+
+	i.draining = true
+	if i.mgr != nil {
+		i.mgr.Drain(ctx)
+	}
+}
+
+// trackInvocation records the start of an invocation from a request.  If the
+// handler is draining or overloaded, this returns the reason for rejecting the
+// request instead.
+func (h *handler) trackInvocation(r *http.Request) (*invocation, string) {
+	h.invocations.l.Lock()
+	defer h.invocations.l.Unlock()
+
+	if h.invocations.draining {
+		return nil, "shutting down"
+	}
+	if h.MaxConcurrentInvocations > 0 && len(h.invocations.running) >= h.MaxConcurrentInvocations {
+		return nil, "too many concurrent invocations"
+	}
+
+	if h.invocations.running == nil {
+		h.invocations.running = map[*invocation]struct{}{}
+	}
+	inv := &invocation{
+		fnID:      r.URL.Query().Get("fnId"),
+		requestID: r.Header.Get(HeaderKeyRequestID),
+		started:   time.Now(),
+	}
+	h.invocations.running[inv] = struct{}{}
+	return inv, ""
+}
+
+func (h *handler) finishInvocation(inv *invocation) {
+	h.invocations.l.Lock()
+	defer h.invocations.l.Unlock()
+
+	delete(h.invocations.running, inv)
+	if h.invocations.draining && len(h.invocations.running) == 0 {
+		select {
+		case <-h.invocations.idle:
+		default:
+			close(h.invocations.idle)
+		}
+	}
+}
+
+// Drain stops the handler from accepting new invocations, which are rejected
+// with a retryable status, and waits for running invocations to finish.
+// Running invocations return control to Inngest at their next step, after
+// flushing any buffered checkpoints, rather than continuing to run further
+// steps.
+//
+// Drain should be called on shutdown before http.Server.Shutdown.  If the
+// context ends before all invocations finish, this returns the context's error
+// and reports the abandoned invocations.
+func (h *handler) Drain(ctx context.Context) (DrainReport, error) {
+	h.invocations.l.Lock()
+	h.invocations.draining = true
+	running := make([]*invocation, 0, len(h.invocations.running))
+	for inv := range h.invocations.running {
+		running = append(running, inv)
+	}
+	if h.invocations.idle == nil {
+		h.invocations.idle = make(chan struct{})
+		if len(running) == 0 {
+			close(h.invocations.idle)
+		}
+	}
+	idle := h.invocations.idle
+	h.invocations.l.Unlock()
+
+	h.Logger.Info("draining invocations", "running", len(running))
+	for _, inv := range running {
+		inv.drain(ctx)
+	}
+
+	select {
+	case <-idle:
+		return DrainReport{Completed: len(running)}, nil
+	case <-ctx.Done():
+	}
+
+	h.invocations.l.Lock()
+	defer h.invocations.l.Unlock()
+
+	report := DrainReport{Completed: len(running) - len(h.invocations.running)}
+	for inv := range h.invocations.running {
+		inv.l.Lock()
+		abandoned := AbandonedInvocation{
+			FunctionID: inv.fnID,
+			RunID:      inv.runID,
+			RequestID:  inv.requestID,
+			Started:    inv.started,
+		}
+		inv.l.Unlock()
+
+		h.Logger.Warn(
+			"abandoning invocation",
+			"fn", abandoned.FunctionID,
+			"run_id", abandoned.RunID,
+			"request_id", abandoned.RequestID,
+		)
+		report.Abandoned = append(report.Abandoned, abandoned)
+	}
+	return report, ctx.Err()
+}
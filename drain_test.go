@@ -0,0 +1,87 @@
+package inngestgo
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrain(t *testing.T) {
+	setup := func(t *testing.T) (Client, *httptest.Server, chan struct{}, chan struct{}) {
+		started := make(chan struct{}, 1)
+		unblock := make(chan struct{})
+		c, err := NewClient(ClientOpts{AppID: "drain", Dev: BoolPtr(true)})
+		require.NoError(t, err)
+		_, err = CreateFunction(
+			c,
+			FunctionOpts{ID: "fn"},
+			EventTrigger("test/event", nil),
+			func(ctx context.Context, input Input[any]) (any, error) {
+				started <- struct{}{}
+				<-unblock
+				return "done", nil
+			},
+		)
+		require.NoError(t, err)
+
+		server := httptest.NewServer(c.Serve())
+		t.Cleanup(server.Close)
+		return c, server, started, unblock
+	}
+
+	invoke := func(t *testing.T, server *httptest.Server) int {
+		body := marshalRequest(t, createRequest(t, Event{Name: "test/event"}))
+		resp, err := http.Post(server.URL+"?fnId=fn", "application/json", bytes.NewReader(body))
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	t.Run("waits for running invocations", func(t *testing.T) {
+		r := require.New(t)
+		c, server, started, unblock := setup(t)
+
+		done := make(chan int)
+		go func() { done <- invoke(t, server) }()
+		<-started
+
+		drained := make(chan DrainReport)
+		go func() {
+			report, err := c.(Drainer).Drain(context.Background())
+			r.NoError(err)
+			drained <- report
+		}()
+
+		// New invocations are rejected while draining.
+		r.Eventually(func() bool {
+			return invoke(t, server) == http.StatusServiceUnavailable
+		}, time.Second, 10*time.Millisecond)
+
+		close(unblock)
+		r.Equal(http.StatusOK, <-done)
+		r.Equal(DrainReport{Completed: 1}, <-drained)
+	})
+
+	t.Run("reports abandoned invocations", func(t *testing.T) {
+		r := require.New(t)
+		c, server, started, unblock := setup(t)
+		defer close(unblock)
+
+		go invoke(t, server)
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		report, err := c.(Drainer).Drain(ctx)
+		r.ErrorIs(err, context.DeadlineExceeded)
+		r.Equal(0, report.Completed)
+		r.Len(report.Abandoned, 1)
+		r.Equal("fn", report.Abandoned[0].FunctionID)
+		r.Equal("run-id", report.Abandoned[0].RunID)
+	})
+}
@@ -0,0 +1,101 @@
+package inngestgo
+
+import (
+	"context"
+
+	"github.com/inngest/inngestgo/internal/fn"
+	"github.com/inngest/inngestgo/step"
+)
+
+// EventType declares an event's name alongside the type of its Data, allowing the
+// same event to be triggered on, sent, waited for, and used for cancellation without
+// repeating its name or risking mismatched payload types:
+//
+//	var SignupCompleted = inngestgo.NewEventType[SignupData]("user/signup.completed")
+//
+//	// Trigger a function.  Passing a handler whose input isn't
+//	// inngestgo.Input[SignupData] is a compile error.
+//	inngestgo.CreateTypedFunction(client, opts, SignupCompleted.Trigger(), handler)
+//
+//	// Send an event.  Passing anything other than SignupData is a compile error.
+//	client.Send(ctx, SignupCompleted.New(SignupData{UserID: "u_123"}))
+//
+//	// Wait for the event within a function.
+//	data, err := SignupCompleted.WaitFor(ctx, "wait-for-signup", step.WaitForEventOpts{
+//		Timeout: time.Hour,
+//	})
+type EventType[T any] struct {
+	name string
+}
+
+// NewEventType returns an EventType for the given event name, whose Data is of
+// type T.
+func NewEventType[T any](name string) EventType[T] {
+	return EventType[T]{name: name}
+}
+
+// Name returns the event name.
+func (e EventType[T]) Name() string {
+	return e.name
+}
+
+// Trigger returns a trigger which runs a function for every event of this type.
+func (e EventType[T]) Trigger() TypedTrigger[T] {
+	return TypedTrigger[T]{Trigger: EventTrigger(e.name, nil)}
+}
+
+// TriggerIf returns a trigger which runs a function for every event of this type
+// matching the given expression.
+func (e EventType[T]) TriggerIf(expression string) TypedTrigger[T] {
+	return TypedTrigger[T]{Trigger: EventTrigger(e.name, &expression)}
+}
+
+// New returns a new event of this type with the given data, which can be sent
+// via Client.Send or step.Send.
+func (e EventType[T]) New(data T) GenericEvent[T] {
+	return GenericEvent[T]{
+		Name: e.name,
+		Data: data,
+	}
+}
+
+// WaitFor pauses function execution until an event of this type is received or the
+// wait times out, returning the event's data, or step.ErrEventNotReceived on
+// timeout.  Any Event set within opts is ignored.
+func (e EventType[T]) WaitFor(ctx context.Context, stepID string, opts step.WaitForEventOpts) (T, error) {
+	opts.Event = e.name
+	evt, err := step.WaitForEvent[GenericEvent[T]](ctx, stepID, opts)
+	return evt.Data, err
+}
+
+// Cancel returns cancellation configuration which cancels a function's runs
+// whenever an event of this type is received.
+func (e EventType[T]) Cancel() ConfigCancel {
+	return ConfigCancel{Event: e.name}
+}
+
+// CancelIf returns cancellation configuration which cancels a function's runs
+// whenever an event of this type matching the given expression is received.
+func (e EventType[T]) CancelIf(expression string) ConfigCancel {
+	return ConfigCancel{Event: e.name, If: &expression}
+}
+
+// TypedTrigger is a trigger for an EventType, carrying the type of the event's
+// Data.  It can be used anywhere a trigger is accepted, and CreateTypedFunction
+// requires the function's input to match it.
+type TypedTrigger[T any] struct {
+	fn.Trigger
+}
+
+// CreateTypedFunction creates a function triggered by an EventType, in the same
+// way as CreateFunction.  The function's input must be Input[T] for the
+// EventType[T] being triggered on, so a mismatched trigger and handler are a
+// compile error rather than a runtime decoding error.
+func CreateTypedFunction[T any](
+	c Client,
+	fc FunctionOpts,
+	trigger TypedTrigger[T],
+	f SDKFunction[T],
+) (ServableFunction, error) {
+	return CreateFunction(c, fc, trigger, f)
+}
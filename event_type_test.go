@@ -0,0 +1,91 @@
+package inngestgo
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/inngest/inngest/pkg/enums"
+	"github.com/inngest/inngestgo/internal/fn"
+	"github.com/inngest/inngestgo/internal/sdkrequest"
+	"github.com/inngest/inngestgo/step"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventType(t *testing.T) {
+	type SignupData struct {
+		UserID string `json:"user_id"`
+	}
+
+	evt := NewEventType[SignupData]("user/signup.completed")
+
+	t.Run("trigger", func(t *testing.T) {
+		r := require.New(t)
+		r.Equal("user/signup.completed", evt.Trigger().Event)
+		r.Nil(evt.Trigger().Expression)
+
+		tr := evt.TriggerIf("event.data.user_id != ''")
+		r.Equal("user/signup.completed", tr.Event)
+		r.Equal("event.data.user_id != ''", *tr.Expression)
+	})
+
+	t.Run("new", func(t *testing.T) {
+		r := require.New(t)
+		e := evt.New(SignupData{UserID: "u_123"})
+		r.Equal("user/signup.completed", e.Name)
+		r.Equal("u_123", e.Data.UserID)
+		r.NoError(e.Validate())
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		r := require.New(t)
+		r.Equal(ConfigCancel{Event: "user/signup.completed"}, evt.Cancel())
+
+		c := evt.CancelIf("async.data.user_id == event.data.user_id")
+		r.Equal("user/signup.completed", c.Event)
+		r.Equal("async.data.user_id == event.data.user_id", *c.If)
+	})
+
+	t.Run("wait for", func(t *testing.T) {
+		r := require.New(t)
+
+		op := sdkrequest.UnhashedOp{Op: enums.OpcodeWaitForEvent, ID: "wait"}
+		mgr := sdkrequest.NewManager(sdkrequest.Opts{
+			Request: &sdkrequest.Request{
+				Steps: map[string]json.RawMessage{
+					op.MustHash(): json.RawMessage(`{"name":"user/signup.completed","data":{"user_id":"u_123"}}`),
+				},
+			},
+			Mode: sdkrequest.StepModeYield,
+		})
+		ctx := sdkrequest.SetManager(context.Background(), mgr)
+
+		got, err := evt.WaitFor(ctx, "wait", step.WaitForEventOpts{
+			Event:   "ignored",
+			Timeout: time.Hour,
+		})
+		r.NoError(err)
+		r.Equal(SignupData{UserID: "u_123"}, got)
+	})
+
+	t.Run("create typed function", func(t *testing.T) {
+		r := require.New(t)
+		c, err := NewClient(ClientOpts{AppID: "event-type", Dev: BoolPtr(true)})
+		r.NoError(err)
+
+		f, err := CreateTypedFunction(
+			c,
+			FunctionOpts{ID: "on-signup"},
+			evt.TriggerIf("event.data.user_id != ''"),
+			func(ctx context.Context, input Input[SignupData]) (any, error) {
+				return input.Event.Data.UserID, nil
+			},
+		)
+		r.NoError(err)
+		r.Equal(
+			[]fn.Trigger{EventTrigger("user/signup.completed", StrPtr("event.data.user_id != ''"))},
+			f.Trigger().Triggers(),
+		)
+	})
+}
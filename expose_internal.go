@@ -21,6 +21,13 @@ type (
 	// concurrency, retry, and flow control configuration.
 	FunctionOpts = fn.FunctionOpts
 
+	// FunctionSchema describes a function's triggering event as JSON Schema.
+	FunctionSchema = fn.FunctionSchema
+
+	// SchemaProvider is optionally implemented by a ServableFunction to describe
+	// its payloads when syncing.
+	SchemaProvider = fn.SchemaProvider
+
 	// ConfigDebounce represents debounce configuration.
 	ConfigDebounce = fn.Debounce
 
@@ -0,0 +1,161 @@
+// Package expr builds CEL expressions for function configuration from typed Go
+// field references.
+//
+// Expressions are used across function configuration:  EventTrigger expressions,
+// WaitForEventOpts.If, cancellation conditions, and keys for throttling, concurrency,
+// singletons, and batching.  Writing these by hand means that typos only surface
+// in production.  This package resolves field references against the JSON tags of
+// your event's Data type, so invalid references are caught when building the
+// expression instead:
+//
+//	type SignupData struct {
+//		UserID string `json:"user_id"`
+//		Plan   string `json:"plan"`
+//	}
+//
+//	userID := func(d *SignupData) any { return &d.UserID }
+//
+//	// "event.data.user_id == async.data.user_id"
+//	match := expr.Eq(expr.Event(userID), expr.Async(userID))
+//
+//	step.WaitForEvent[any](ctx, "wait", step.WaitForEventOpts{
+//		Event:   "user/signup.completed",
+//		If:      match.Ptr(),
+//		Timeout: time.Hour,
+//	})
+//
+// Selector functions are checked by the Go compiler.  When a path is only known as
+// a string, use EventPath or AsyncPath, which validate the path against the type
+// and return an error for unknown fields.
+package expr
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Expr is a rendered CEL expression.
+type Expr struct {
+	s string
+	// bin records whether the expression is a binary or logical operation, and
+	// therefore needs parentheses when nested within other operations.
+	bin bool
+}
+
+// String returns the CEL expression.
+func (e Expr) String() string {
+	return e.s
+}
+
+// Ptr returns a pointer to the CEL expression.  Most function options accept
+// optional expressions as *string, eg. ConfigThrottle.Key or ConfigCancel.If.
+func (e Expr) Ptr() *string {
+	s := e.s
+	return &s
+}
+
+// Raw wraps a hand-written CEL expression.  No validation is performed.
+func Raw(s string) Expr {
+	return Expr{s: s, bin: true}
+}
+
+// Lit returns a literal value.  Strings are quoted, and all other values are
+// rendered as JSON, which is compatible with CEL for numbers, bools, null,
+// lists and maps.
+func Lit(v any) Expr {
+	switch t := v.(type) {
+	case Expr:
+		return t
+	case string:
+		return Expr{s: strconv.Quote(t)}
+	}
+
+	byt, err := json.Marshal(v)
+	if err != nil {
+		// Literals are defined in code, so an unrepresentable literal is a
+		// programming error.
+		panic(fmt.Errorf("expr: unable to render literal %v: %w", v, err))
+	}
+	return Expr{s: string(byt)}
+}
+
+// Eq returns "a == b".  Any argument which isn't an Expr is treated as a literal.
+func Eq(a, b any) Expr {
+	return binary("==", a, b)
+}
+
+// Neq returns "a != b".  Any argument which isn't an Expr is treated as a literal.
+func Neq(a, b any) Expr {
+	return binary("!=", a, b)
+}
+
+// Gt returns "a > b".  Any argument which isn't an Expr is treated as a literal.
+func Gt(a, b any) Expr {
+	return binary(">", a, b)
+}
+
+// Gte returns "a >= b".  Any argument which isn't an Expr is treated as a literal.
+func Gte(a, b any) Expr {
+	return binary(">=", a, b)
+}
+
+// Lt returns "a < b".  Any argument which isn't an Expr is treated as a literal.
+func Lt(a, b any) Expr {
+	return binary("<", a, b)
+}
+
+// Lte returns "a <= b".  Any argument which isn't an Expr is treated as a literal.
+func Lte(a, b any) Expr {
+	return binary("<=", a, b)
+}
+
+// In returns "a in b", eg. checking whether a value exists within a list.
+func In(a, b any) Expr {
+	return binary("in", a, b)
+}
+
+// And joins the given expressions with "&&".
+func And(exprs ...Expr) Expr {
+	return join("&&", exprs)
+}
+
+// Or joins the given expressions with "||".
+func Or(exprs ...Expr) Expr {
+	return join("||", exprs)
+}
+
+// Not negates the given expression.
+func Not(e Expr) Expr {
+	return Expr{s: "!" + wrap(e)}
+}
+
+func binary(op string, a, b any) Expr {
+	return Expr{
+		s:   fmt.Sprintf("%s %s %s", wrap(Lit(a)), op, wrap(Lit(b))),
+		bin: true,
+	}
+}
+
+func join(op string, exprs []Expr) Expr {
+	switch len(exprs) {
+	case 0:
+		return Expr{s: "true"}
+	case 1:
+		return exprs[0]
+	}
+
+	parts := make([]string, len(exprs))
+	for i, e := range exprs {
+		parts[i] = wrap(e)
+	}
+	return Expr{s: strings.Join(parts, " "+op+" "), bin: true}
+}
+
+func wrap(e Expr) string {
+	if e.bin {
+		return "(" + e.s + ")"
+	}
+	return e.s
+}
@@ -0,0 +1,133 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type account struct {
+	ID   string `json:"id"`
+	Tier int    `json:"tier,omitempty"`
+}
+
+type meta struct {
+	Source string `json:"source"`
+}
+
+type signupData struct {
+	meta
+
+	UserID  string         `json:"user_id"`
+	Email   string         // No tag; the field name is used.
+	Account *account       `json:"account"`
+	Tags    []string       `json:"tags"`
+	Extra   map[string]any `json:"extra"`
+	Dashed  string         `json:"first-name"`
+	Secret  string         `json:"-"`
+}
+
+func TestEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		sel      func(d *signupData) any
+		expected string
+	}{
+		{"json tag", func(d *signupData) any { return &d.UserID }, "event.data.user_id"},
+		{"field name", func(d *signupData) any { return &d.Email }, "event.data.Email"},
+		{"nested pointer struct", func(d *signupData) any { return &d.Account.ID }, "event.data.account.id"},
+		{"pointer field", func(d *signupData) any { return &d.Account }, "event.data.account"},
+		{"tag options", func(d *signupData) any { return &d.Account.Tier }, "event.data.account.tier"},
+		{"embedded struct", func(d *signupData) any { return &d.Source }, "event.data.source"},
+		{"non-identifier", func(d *signupData) any { return &d.Dashed }, `event.data["first-name"]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, Event(test.sel).String())
+		})
+	}
+}
+
+func TestFieldErrors(t *testing.T) {
+	t.Run("ignored field", func(t *testing.T) {
+		_, err := Field(RootEvent, func(d *signupData) any { return &d.Secret })
+		require.Error(t, err)
+	})
+
+	t.Run("non-pointer", func(t *testing.T) {
+		_, err := Field(RootEvent, func(d *signupData) any { return d.UserID })
+		require.Error(t, err)
+	})
+
+	t.Run("root value", func(t *testing.T) {
+		_, err := Field(RootEvent, func(d *signupData) any { return d })
+		require.Error(t, err)
+	})
+
+	t.Run("panics in Event", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Event(func(d *signupData) any { return nil })
+		})
+	})
+}
+
+func TestPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+		err      bool
+	}{
+		{path: "user_id", expected: "async.data.user_id"},
+		{path: "account.id", expected: "async.data.account.id"},
+		{path: "source", expected: "async.data.source"},
+		{path: "tags.0", expected: "async.data.tags[0]"},
+		{path: "extra.anything.goes", expected: "async.data.extra.anything.goes"},
+		{path: "user_idd", err: true},
+		{path: "account.name", err: true},
+		{path: "tags.first", err: true},
+		{path: "Secret", err: true},
+		{path: "", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			e, err := AsyncPath[signupData](test.path)
+			if test.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, e.String())
+		})
+	}
+}
+
+func TestOperators(t *testing.T) {
+	userID := func(d *signupData) any { return &d.UserID }
+	tier := func(d *signupData) any { return &d.Account.Tier }
+
+	assert.Equal(
+		t,
+		"event.data.user_id == async.data.user_id",
+		Eq(Event(userID), Async(userID)).String(),
+	)
+	assert.Equal(
+		t,
+		`(event.data.user_id != "admin") && (event.data.account.tier >= 2)`,
+		And(Neq(Event(userID), "admin"), Gte(Event(tier), 2)).String(),
+	)
+	assert.Equal(
+		t,
+		`!((event.data.account.tier < 1) || (event.data.account.tier > 3))`,
+		Not(Or(Lt(Event(tier), 1), Gt(Event(tier), 3))).String(),
+	)
+	assert.Equal(
+		t,
+		`event.data.user_id in ["a","b"]`,
+		In(Event(userID), []string{"a", "b"}).String(),
+	)
+	assert.Equal(t, "true", And().String())
+	assert.Equal(t, "event.data.user_id", *Event(userID).Ptr())
+}
@@ -0,0 +1,270 @@
+package expr
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// RootEvent is the root for fields within the triggering event.
+	RootEvent = "event"
+	// RootAsync is the root for fields within an incoming event, used when
+	// matching events in WaitForEventOpts.If and ConfigCancel.If.
+	RootAsync = "async"
+)
+
+// maxDepth bounds how deeply nested pointer structs are allocated when resolving
+// selectors, protecting against self-referential types.
+const maxDepth = 16
+
+var identRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Event returns a reference to a field within the triggering event's data, eg.
+// "event.data.user_id".  T is the event's Data type and sel must return a pointer
+// to one of its fields:
+//
+//	expr.Event(func(d *SignupData) any { return &d.Account.ID })
+//
+// Event panics if sel doesn't return a pointer to a field within T.
+func Event[T any](sel func(d *T) any) Expr {
+	return must(Field(RootEvent, sel))
+}
+
+// Async returns a reference to a field within an incoming event's data, eg.
+// "async.data.user_id".  This is used when matching events within waits and
+// cancellations.
+//
+// Async panics if sel doesn't return a pointer to a field within T.
+func Async[T any](sel func(d *T) any) Expr {
+	return must(Field(RootAsync, sel))
+}
+
+// EventPath returns a reference to a field within the triggering event's data
+// using a dot-separated path of JSON field names, eg. "account.id".  An error is
+// returned if the path doesn't exist within T.
+func EventPath[T any](path string) (Expr, error) {
+	return Path[T](RootEvent, path)
+}
+
+// AsyncPath returns a reference to a field within an incoming event's data using
+// a dot-separated path of JSON field names.  An error is returned if the path
+// doesn't exist within T.
+func AsyncPath[T any](path string) (Expr, error) {
+	return Path[T](RootAsync, path)
+}
+
+// Field returns a reference to the field selected by sel within the data of the
+// given root, eg. RootEvent.
+func Field[T any](root string, sel func(d *T) any) (Expr, error) {
+	segments, err := resolveSelector(sel)
+	if err != nil {
+		return Expr{}, err
+	}
+	return render(root, segments), nil
+}
+
+// Path returns a reference to the dot-separated path of JSON field names within
+// the data of the given root, eg. RootEvent.
+func Path[T any](root string, path string) (Expr, error) {
+	if path == "" {
+		return Expr{}, fmt.Errorf("expr: empty path")
+	}
+
+	segments := strings.Split(path, ".")
+	typ := reflect.TypeFor[T]()
+	for i, seg := range segments {
+		next, ok := lookup(typ, seg)
+		if !ok {
+			return Expr{}, fmt.Errorf(
+				"expr: field %q not found in %s",
+				strings.Join(segments[:i+1], "."),
+				reflect.TypeFor[T](),
+			)
+		}
+		typ = next
+	}
+
+	return render(root, segments), nil
+}
+
+func must(e Expr, err error) Expr {
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+func render(root string, segments []string) Expr {
+	var sb strings.Builder
+	sb.WriteString(root)
+	sb.WriteString(".data")
+	for _, seg := range segments {
+		if identRegexp.MatchString(seg) {
+			sb.WriteString(".")
+			sb.WriteString(seg)
+			continue
+		}
+		if _, err := strconv.Atoi(seg); err == nil {
+			sb.WriteString("[" + seg + "]")
+			continue
+		}
+		sb.WriteString("[" + strconv.Quote(seg) + "]")
+	}
+	return Expr{s: sb.String()}
+}
+
+// resolveSelector calls sel with a zero T, then finds the JSON path of the field
+// whose address sel returned.
+func resolveSelector[T any](sel func(d *T) any) ([]string, error) {
+	root := reflect.New(reflect.TypeFor[T]())
+	allocate(root.Elem(), 0)
+
+	ptr := reflect.ValueOf(sel(root.Interface().(*T)))
+	if !ptr.IsValid() || ptr.Kind() != reflect.Pointer || ptr.IsNil() {
+		return nil, fmt.Errorf("expr: selector must return a pointer to a field of %s", reflect.TypeFor[T]())
+	}
+
+	segments, ok := find(root.Elem(), ptr, 0)
+	if !ok {
+		return nil, fmt.Errorf("expr: selector must return a pointer to a field of %s", reflect.TypeFor[T]())
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("expr: selector must return a field of %s, not the value itself", reflect.TypeFor[T]())
+	}
+	return segments, nil
+}
+
+// allocate initializes nil pointers to structs so that selectors may traverse
+// them without panicking.
+func allocate(v reflect.Value, depth int) {
+	if depth > maxDepth || v.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if !f.CanSet() {
+			continue
+		}
+		if f.Kind() == reflect.Pointer && f.Type().Elem().Kind() == reflect.Struct {
+			f.Set(reflect.New(f.Type().Elem()))
+			allocate(f.Elem(), depth+1)
+			continue
+		}
+		allocate(f, depth+1)
+	}
+}
+
+// find returns the JSON path to the field within v at the address of ptr, with the
+// same type as ptr's element.
+func find(v reflect.Value, ptr reflect.Value, depth int) ([]string, bool) {
+	if depth > maxDepth {
+		return nil, false
+	}
+	if v.CanAddr() && v.Addr().Pointer() == ptr.Pointer() && v.Type() == ptr.Type().Elem() {
+		return []string{}, true
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		sf := v.Type().Field(i)
+		if !sf.IsExported() && !sf.Anonymous {
+			continue
+		}
+		name, inline, skip := jsonName(sf)
+		if skip {
+			continue
+		}
+		rest, ok := find(v.Field(i), ptr, depth+1)
+		if !ok {
+			continue
+		}
+		if inline {
+			return rest, true
+		}
+		return append([]string{name}, rest...), true
+	}
+	return nil, false
+}
+
+// lookup returns the type of the given JSON field within typ.
+func lookup(typ reflect.Type, name string) (reflect.Type, bool) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Interface:
+		// Untyped data may contain any field.
+		return typ, true
+	case reflect.Map:
+		if typ.Key().Kind() != reflect.String {
+			return nil, false
+		}
+		return typ.Elem(), true
+	case reflect.Slice, reflect.Array:
+		if _, err := strconv.Atoi(name); err != nil {
+			return nil, false
+		}
+		return typ.Elem(), true
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			sf := typ.Field(i)
+			if !sf.IsExported() && !sf.Anonymous {
+				continue
+			}
+			jn, inline, skip := jsonName(sf)
+			if skip {
+				continue
+			}
+			if inline {
+				if t, ok := lookup(sf.Type, name); ok {
+					return t, true
+				}
+				continue
+			}
+			if jn == name {
+				return sf.Type, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// jsonName returns the JSON name for a struct field, whether the field is an
+// embedded struct whose fields are inlined, and whether it's ignored entirely.
+// This mirrors the rules used by encoding/json.
+func jsonName(sf reflect.StructField) (name string, inline bool, skip bool) {
+	tag := sf.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	name, _, _ = strings.Cut(tag, ",")
+	if name == "" && sf.Anonymous {
+		t := sf.Type
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Struct {
+			return "", true, false
+		}
+	}
+	if !sf.IsExported() {
+		// Unexported embedded non-struct types aren't serialized.
+		return "", false, true
+	}
+	if name == "" {
+		name = sf.Name
+	}
+	return name, false, false
+}
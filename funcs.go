@@ -9,6 +9,7 @@ import (
 	"github.com/gosimple/slug"
 	"github.com/inngest/inngestgo/internal/event"
 	"github.com/inngest/inngestgo/internal/fn"
+	"github.com/inngest/inngestgo/pkg/jsonschema"
 )
 
 // Slugify converts a string to a slug. This is only useful for replicating the
@@ -184,3 +185,11 @@ func (s servableFunc) ZeroEvent() any {
 func (s servableFunc) Func() any {
 	return s.f
 }
+
+// Schema describes the function's triggering event, derived from its event type.
+// Step outputs aren't described; see FunctionSchema.
+func (s servableFunc) Schema() *FunctionSchema {
+	return &FunctionSchema{
+		Event: jsonschema.Reflect(s.ZeroType().Type()),
+	}
+}
@@ -0,0 +1,60 @@
+package inngestgo
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/inngest/inngestgo/pkg/jsonschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFunctionSchema(t *testing.T) {
+	type SignupData struct {
+		Email string `json:"email"`
+		Plan  string `json:"plan,omitempty"`
+	}
+
+	r := require.New(t)
+
+	c, err := NewClient(ClientOpts{AppID: "app", Dev: Ptr(true)})
+	r.NoError(err)
+
+	f, err := CreateFunction(
+		c,
+		FunctionOpts{ID: "signup"},
+		EventTrigger("user/signed.up", nil),
+		func(ctx context.Context, input Input[SignupData]) (any, error) {
+			return nil, nil
+		},
+	)
+	r.NoError(err)
+
+	schema := f.(SchemaProvider).Schema()
+	r.NotNil(schema)
+	r.Equal(jsonschema.Types{"object"}, schema.Event.Type)
+	r.Equal([]string{"name", "data"}, schema.Event.Required)
+	r.Equal(&jsonschema.Schema{
+		Type: jsonschema.Types{"object"},
+		Properties: map[string]*jsonschema.Schema{
+			"email": {Type: jsonschema.Types{"string"}},
+			"plan":  {Type: jsonschema.Types{"string"}},
+		},
+		Required: []string{"email"},
+	}, schema.Event.Properties["data"])
+
+	h := c.(*apiClient).h
+	configs, err := createFunctionConfigs(h.appName, h.GetFunctions(), url.URL{Scheme: "http", Host: "localhost"}, false)
+	r.NoError(err)
+	r.Len(configs, 1)
+
+	byt, err := json.Marshal(configs[0])
+	r.NoError(err)
+
+	var synced struct {
+		Schema FunctionSchema `json:"schema"`
+	}
+	r.NoError(json.Unmarshal(byt, &synced))
+	r.Equal(schema.Event.Properties["data"], synced.Schema.Event.Properties["data"])
+}
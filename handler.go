@@ -8,18 +8,22 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"net/http"
 	"net/url"
 	"os"
 	"reflect"
 	"runtime/debug"
+	"runtime/metrics"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/inngest/inngest/pkg/enums"
 	"github.com/inngest/inngest/pkg/publicerr"
 	"github.com/inngest/inngest/pkg/syscode"
+	"github.com/inngest/inngestgo/connect"
 	sdkerrors "github.com/inngest/inngestgo/errors"
 	"github.com/inngest/inngestgo/internal"
 	"github.com/inngest/inngestgo/internal/event"
@@ -30,7 +34,7 @@ import (
 	"github.com/inngest/inngestgo/internal/util"
 	"github.com/inngest/inngestgo/middleware"
 	"github.com/inngest/inngestgo/pkg/env"
-	"github.com/inngest/inngestgo/pkg/httputil"
+	"github.com/inngest/inngestgo/pkg/signingkey"
 	"github.com/inngest/inngestgo/step"
 )
 
@@ -45,6 +49,10 @@ var (
 	// invoke request (100MB).
 	DefaultMaxBodySize = 1024 * 1024 * 100
 
+	// defaultOverloadRetryAfter is the delay requested from Inngest when
+	// rejecting invocations due to load.
+	defaultOverloadRetryAfter = 5 * time.Second
+
 	capabilities = types.Capabilities{
 		InBandSync: types.InBandSyncV1,
 		TrustProbe: types.TrustProbeV1,
@@ -65,6 +73,14 @@ type handlerOpts struct {
 	// defaults to os.Getenv("INNGEST_SIGNING_KEY_FALLBACK").
 	SigningKeyFallback *string
 
+	// SigningKeyProvider, if set, supplies the signing keys for every request,
+	// overriding SigningKey and SigningKeyFallback.
+	SigningKeyProvider SigningKeyProvider
+
+	// ReplayStore, if set, records the signature of every signed request from
+	// Inngest, rejecting requests whose signature has already been used.
+	ReplayStore ReplayStore
+
 	// APIOrigin is the specified host to be used to make API calls
 	APIBaseURL *string
 
@@ -96,7 +112,12 @@ type handlerOpts struct {
 	// whenever code within one of your Inngest function or any dependency thereof changes.
 	AppVersion *string
 
-	// MaxBodySize is the max body size to read for incoming invoke requests
+	// FingerprintFunctions appends a fingerprint of the app's functions to the
+	// app version.
+	FingerprintFunctions bool
+
+	// MaxBodySize is the max body size to read for incoming invoke requests.
+	// Compressed bodies are limited both before and after decompression.
 	MaxBodySize int
 
 	// URL that the function is served at.  If not supplied this is taken from
@@ -107,11 +128,35 @@ type handlerOpts struct {
 	// differs from true streaming in that we don't support server-sent events.
 	UseStreaming bool
 
+	// UseServerSentEvents streams invocations as server-sent events: a
+	// "heartbeat" event keeps the connection alive, a "step" event is sent as
+	// each step op is reported, and a final "result" event contains the
+	// StreamResponse.  Events are only sent to requests which accept
+	// "text/event-stream"; other requests receive the usual JSON response.  This
+	// takes precedence over UseStreaming.
+	UseServerSentEvents bool
+
 	// EnableUnauthedSync allows unsigned sync requests in cloud mode.  Dev mode
 	// always allows unsigned sync requests because the Dev Server does not sign
 	// them.
 	EnableUnauthedSync *bool
 
+	// MaxConcurrentInvocations limits the number of concurrent invocations.  If
+	// zero, invocations are unlimited.
+	MaxConcurrentInvocations int
+
+	// MaxHeapBytes rejects invocations while the Go heap exceeds the given size.
+	// If zero, memory isn't checked.
+	MaxHeapBytes uint64
+
+	// DetailedInspection includes each function's config and invocation stats
+	// in signed inspection responses.
+	DetailedInspection bool
+
+	// OverloadRetryAfter is the delay requested from Inngest when rejecting
+	// invocations due to load.
+	OverloadRetryAfter time.Duration
+
 	Dev *bool
 }
 
@@ -121,10 +166,7 @@ type handlerOpts struct {
 // This is the private key used to register functions and communicate with the private
 // API.
 func (h handlerOpts) GetSigningKey() string {
-	if h.SigningKey == nil {
-		return os.Getenv("INNGEST_SIGNING_KEY")
-	}
-	return *h.SigningKey
+	return h.signingKeys(context.Background()).Current
 }
 
 // GetSigningKeyFallback returns the signing key fallback defined within
@@ -134,10 +176,44 @@ func (h handlerOpts) GetSigningKey() string {
 // with the private API. If a request fails auth with the signing key then we'll
 // try again with the fallback
 func (h handlerOpts) GetSigningKeyFallback() string {
-	if h.SigningKeyFallback == nil {
-		return os.Getenv("INNGEST_SIGNING_KEY_FALLBACK")
+	return h.signingKeys(context.Background()).Fallback
+}
+
+// signingKeys returns the signing key and fallback signing key.  If a
+// SigningKeyProvider is set, both keys come from a single call so that they're
+// consistent while keys are rotated.  If the provider fails, this logs the error
+// and falls back to the static keys.
+func (h handlerOpts) signingKeys(ctx context.Context) signingkey.Keys {
+	if h.SigningKeyProvider != nil {
+		keys, err := h.SigningKeyProvider.SigningKeys(ctx)
+		if err == nil {
+			return keys
+		}
+		if h.Logger != nil {
+			h.Logger.Error("error loading signing keys from provider", "error", err)
+		}
 	}
-	return *h.SigningKeyFallback
+
+	keys := signingkey.Keys{
+		Current:  os.Getenv("INNGEST_SIGNING_KEY"),
+		Fallback: os.Getenv("INNGEST_SIGNING_KEY_FALLBACK"),
+	}
+	if h.SigningKey != nil {
+		keys.Current = *h.SigningKey
+	}
+	if h.SigningKeyFallback != nil {
+		keys.Fallback = *h.SigningKeyFallback
+	}
+	return keys
+}
+
+// capabilities returns the capabilities advertised when syncing.
+func (h handlerOpts) capabilities() types.Capabilities {
+	c := capabilities
+	if h.UseServerSentEvents {
+		c.ServerSentEvents = types.ServerSentEventsV1
+	}
+	return c
 }
 
 // GetAPIOrigin returns the host to use for sending API requests
@@ -269,14 +345,43 @@ type handler struct {
 	funcs   []ServableFunction
 	// lock prevents reading the function maps while serving
 	l sync.RWMutex
+	// invocations tracks the invocations currently being served.
+	invocations invocations
+
+	// statusL guards the status reported by readiness checks and inspection.
+	statusL  sync.Mutex
+	lastSync *SyncStatus
+	conn     connect.WorkerConnection
+	stats    map[string]*functionStats
+
+	// syncOnce ensures that the app is only synced once on start.
+	syncOnce sync.Once
+	// pendingSync is the sync scheduled by ServeOpts.SyncOnStart, which starts
+	// on the first request.
+	pendingSync atomic.Pointer[SyncOpts]
 }
 
 func (h *handler) GetAppName() string {
 	return h.appName
 }
 
+// GetAppVersion returns the app version, including a fingerprint of the app's
+// functions if enabled.
 func (h *handler) GetAppVersion() *string {
-	return h.AppVersion
+	if !h.FingerprintFunctions {
+		return h.AppVersion
+	}
+
+	fingerprint, err := functionsFingerprint(h.funcs)
+	if err != nil {
+		h.Logger.Error("error fingerprinting functions", "error", err)
+		return h.AppVersion
+	}
+	if h.AppVersion == nil || *h.AppVersion == "" {
+		return &fingerprint
+	}
+	version := *h.AppVersion + "+" + fingerprint
+	return &version
 }
 
 func (h *handler) GetFunctions() []ServableFunction {
@@ -315,6 +420,9 @@ func (h *handler) Register(funcs ...ServableFunction) {
 
 	for _, f := range funcs {
 		slugs[f.FullyQualifiedID()] = f
+		for _, companion := range h.companions(f) {
+			slugs[companion.FullyQualifiedID()] = companion
+		}
 	}
 
 	newFuncs := make([]ServableFunction, len(slugs))
@@ -327,8 +435,23 @@ func (h *handler) Register(funcs ...ServableFunction) {
 	h.funcs = newFuncs
 }
 
+// companions returns the functions registered alongside the given function, such
+// as its OnFailure and OnCancel handlers.
+func (h *handler) companions(f ServableFunction) []ServableFunction {
+	var companions []ServableFunction
+	fc := f.Config()
+	if fc.OnFailure != nil {
+		companions = append(companions, fc.OnFailure.FailureFunction(f))
+	}
+	if fc.OnCancel != nil {
+		companions = append(companions, fc.OnCancel.CancelFunction(f, h.fetchRunSteps))
+	}
+	return companions
+}
+
 func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.Logger.Debug("received http request", "method", r.Method)
+	h.startSync()
 	SetBasicResponseHeaders(w)
 
 	switch r.Method {
@@ -465,6 +588,7 @@ func (h *handler) register(w http.ResponseWriter, r *http.Request) error {
 			"syncKind", syncKind,
 		)
 	}
+	h.recordSync(syncKind, err)
 	return err
 }
 
@@ -510,7 +634,7 @@ func (h *handler) inBandSync(
 	if max == 0 {
 		max = DefaultMaxBodySize
 	}
-	reqByt, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(max)))
+	reqByt, err := readBody(w, r, max)
 	if err != nil {
 		return publicerr.Error{
 			Err:    fmt.Errorf("error reading request body"),
@@ -518,11 +642,12 @@ func (h *handler) inBandSync(
 		}
 	}
 
+	keys := h.signingKeys(ctx)
 	valid, skey, err := ValidateRequestSignature(
 		ctx,
 		sig,
-		h.GetSigningKey(),
-		h.GetSigningKeyFallback(),
+		keys.Current,
+		keys.Fallback,
 		reqByt,
 		false,
 	)
@@ -532,6 +657,9 @@ func (h *handler) inBandSync(
 	if !valid {
 		return errUnauthorized
 	}
+	if err := h.rejectReplay(ctx, r, sig); err != nil {
+		return err
+	}
 
 	var reqBody inBandSynchronizeRequest
 	err = json.Unmarshal(reqByt, &reqBody)
@@ -577,7 +705,7 @@ func (h *handler) inBandSync(
 		env = &val
 	}
 
-	inspection, err := h.createSecureInspection()
+	inspection, err := h.createSecureInspection(ctx)
 	if err != nil {
 		return fmt.Errorf("error creating inspection: %w", err)
 	}
@@ -619,9 +747,6 @@ func (h *handler) inBandSync(
 }
 
 func (h *handler) outOfBandSync(w http.ResponseWriter, r *http.Request) error {
-	h.l.Lock()
-	defer h.l.Unlock()
-
 	// Get the sync ID from the URL and then remove it, since we don't want the
 	// sync ID to show in the function URLs (that would affect the checksum and
 	// is ugly in the UI)
@@ -630,24 +755,33 @@ func (h *handler) outOfBandSync(w http.ResponseWriter, r *http.Request) error {
 	qp.Del("deployId")
 	r.URL.RawQuery = qp.Encode()
 
-	appURL, err := url.Parse(fmt.Sprintf(
-		"%s://%s%s?%s",
-		httputil.GetScheme(r),
-		r.Host,
-		r.URL.Path,
-		r.URL.RawQuery,
-	))
+	appURL, err := h.appURL(r)
 	if err != nil {
-		return fmt.Errorf("error parsing request URL: %w", err)
+		return err
 	}
-	appURL, err = overrideURL(appURL, h.handlerOpts)
-	if err != nil {
-		return fmt.Errorf("error overriding request URL: %w", err)
+
+	if err := h.registerApp(context.WithoutCancel(r.Context()), appURL, syncID, r.Header.Get(HeaderKeyServerKind)); err != nil {
+		return err
 	}
 
+	w.Header().Add(HeaderKeySyncKind, SyncKindOutOfBand)
+
+	return nil
+}
+
+// registerApp syncs the app's functions with Inngest, serving them from the
+// given URL.  The functions are read under the handler's read lock, which is
+// released before registering so that requests aren't blocked on the network.
+func (h *handler) registerApp(ctx context.Context, appURL *url.URL, syncID string, serverKind string) error {
+	h.l.RLock()
 	appVersion := ""
-	if h.AppVersion != nil {
-		appVersion = *h.AppVersion
+	if v := h.GetAppVersion(); v != nil {
+		appVersion = *v
+	}
+	fns, err := createFunctionConfigs(h.appName, h.funcs, *appURL, false)
+	h.l.RUnlock()
+	if err != nil {
+		return fmt.Errorf("error creating function configs: %w", err)
 	}
 
 	config := types.RegisterRequest{
@@ -660,15 +794,17 @@ func (h *handler) outOfBandSync(w http.ResponseWriter, r *http.Request) error {
 			Env:      h.GetEnv(),
 			Platform: platform(),
 		},
-		Capabilities: capabilities,
+		Capabilities: h.capabilities(),
 		AppVersion:   appVersion,
+		Functions:    fns,
 	}
 
-	fns, err := createFunctionConfigs(h.appName, h.funcs, *appURL, false)
+	// Replicas syncing identical config to the same URL share an idempotency
+	// key, so that Inngest only syncs once per change.
+	config.IdempotencyKey, err = syncHash(fns, appVersion, appURL, h.handlerOpts)
 	if err != nil {
-		return fmt.Errorf("error creating function configs: %w", err)
+		return err
 	}
-	config.Functions = fns
 
 	registerURL := fmt.Sprintf("%s/fn/register", h.GetAPIBaseURL())
 	if h.RegisterURL != nil {
@@ -681,7 +817,7 @@ func (h *handler) outOfBandSync(w http.ResponseWriter, r *http.Request) error {
 			return nil, fmt.Errorf("error marshalling function config: %w", err)
 		}
 
-		req, err := http.NewRequest(http.MethodPost, registerURL, bytes.NewReader(byt))
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, registerURL, bytes.NewReader(byt))
 		if err != nil {
 			return nil, fmt.Errorf("error creating new request: %w", err)
 		}
@@ -693,11 +829,8 @@ func (h *handler) outOfBandSync(w http.ResponseWriter, r *http.Request) error {
 
 		// If the request specifies a server kind then include it as an expectation
 		// in the outgoing request
-		if r.Header.Get(HeaderKeyServerKind) != "" {
-			req.Header.Set(
-				HeaderKeyExpectedServerKind,
-				r.Header.Get(HeaderKeyServerKind),
-			)
+		if serverKind != "" {
+			req.Header.Set(HeaderKeyExpectedServerKind, serverKind)
 		}
 
 		if h.GetEnv() != "" {
@@ -709,25 +842,33 @@ func (h *handler) outOfBandSync(w http.ResponseWriter, r *http.Request) error {
 		return req, nil
 	}
 
+	keys := h.signingKeys(ctx)
 	resp, err := fetchWithAuthFallback(
 		createRequest,
-		h.GetSigningKey(),
-		h.GetSigningKeyFallback(),
+		keys.Current,
+		keys.Fallback,
 	)
 	if err != nil {
-		return fmt.Errorf("error performing registration request: %w", err)
+		return &registerError{err: fmt.Errorf("error performing registration request: %w", err)}
 	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
 	if resp.StatusCode > 299 {
 		body := map[string]any{}
 		byt, _ := io.ReadAll(resp.Body)
 		if err := json.Unmarshal(byt, &body); err != nil {
-			return fmt.Errorf("error reading register response: %w\n\n%s", err, byt)
+			return &registerError{
+				statusCode: resp.StatusCode,
+				err:        fmt.Errorf("error reading register response: %w\n\n%s", err, byt),
+			}
+		}
+		return &registerError{
+			statusCode: resp.StatusCode,
+			err:        fmt.Errorf("error registering functions: %s", body["error"]),
 		}
-		return fmt.Errorf("error registering functions: %s", body["error"])
 	}
 
-	w.Header().Add(HeaderKeySyncKind, SyncKindOutOfBand)
-
 	return nil
 }
 
@@ -755,6 +896,81 @@ func createFunctionConfigs(
 	return fnConfigs, nil
 }
 
+// admitHeap rejects the request with a retryable status and returns false if the
+// heap exceeds MaxHeapBytes, so that Inngest backs off rather than the process
+// running out of memory.  This is checked before the request's body is read, as
+// reading the body adds to the heap.
+func (h *handler) admitHeap(w http.ResponseWriter, r *http.Request) bool {
+	if h.MaxHeapBytes > 0 {
+		if heap := heapBytes(); heap > h.MaxHeapBytes {
+			h.rejectOverloaded(w, r, "memory limit exceeded", "heap_bytes", heap)
+			return false
+		}
+	}
+	return true
+}
+
+// admitInvocation reserves capacity for an invocation.  If the handler is
+// draining or at its concurrency limit this rejects the request with a retryable
+// status and returns false.  The invocation must be finished once the function
+// returns.
+func (h *handler) admitInvocation(w http.ResponseWriter, r *http.Request) (*invocation, bool) {
+	inv, reason := h.trackInvocation(r)
+	if inv == nil {
+		h.rejectOverloaded(w, r, reason)
+		return nil, false
+	}
+	return inv, true
+}
+
+func (h *handler) rejectOverloaded(w http.ResponseWriter, r *http.Request, reason string, attrs ...any) {
+	retryAfter := h.OverloadRetryAfter
+	if retryAfter <= 0 {
+		retryAfter = defaultOverloadRetryAfter
+	}
+
+	h.Logger.Warn(
+		"rejecting invocation",
+		append([]any{
+			"reason", reason,
+			"fn", r.URL.Query().Get("fnId"),
+			"request_id", r.Header.Get(HeaderKeyRequestID),
+		}, attrs...)...,
+	)
+
+	_ = r.Body.Close()
+	w.Header().Set(HeaderKeyRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	w.WriteHeader(http.StatusServiceUnavailable)
+	_ = json.NewEncoder(w).Encode(sdkrequest.ErrorResponse{
+		Message: fmt.Sprintf("overloaded: %s", reason),
+	})
+}
+
+// heapBytes returns the size of live and unswept heap objects.
+func heapBytes() uint64 {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
+
+// function returns the registered function with the given ID, or nil if the
+// function isn't registered.
+func (h *handler) function(fnID string) ServableFunction {
+	h.l.RLock()
+	defer h.l.RUnlock()
+
+	for _, f := range h.funcs {
+		isOldFormat := f.ID() == fnID // Only include function slug
+		if f.FullyQualifiedID() == fnID || isOldFormat {
+			return f
+		}
+	}
+	return nil
+}
+
 // invoke handles incoming POST calls to invoke a function, delegating to invoke() after validating
 // the request.
 func (h *handler) invoke(w http.ResponseWriter, r *http.Request) error {
@@ -775,21 +991,26 @@ func (h *handler) invoke(w http.ResponseWriter, r *http.Request) error {
 		}
 	}
 
+	if !h.admitHeap(w, r) {
+		return nil
+	}
+
 	max := h.MaxBodySize
 	if max == 0 {
 		max = DefaultMaxBodySize
 	}
-	byt, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(max)))
+	byt, err := readBody(w, r, max)
 	if err != nil {
 		h.Logger.Error("error decoding function request", "error", err)
 		return fmt.Errorf("%w: %s", errBadRequest, err)
 	}
 
+	keys := h.signingKeys(r.Context())
 	if valid, _, err := ValidateRequestSignature(
 		r.Context(),
 		sig,
-		h.GetSigningKey(),
-		h.GetSigningKeyFallback(),
+		keys.Current,
+		keys.Fallback,
 		byt,
 		h.isDev(),
 	); !valid {
@@ -797,6 +1018,28 @@ func (h *handler) invoke(w http.ResponseWriter, r *http.Request) error {
 		return errUnauthorized
 	}
 
+	// Admit the invocation once it's authenticated, so that unauthenticated
+	// requests can neither see nor use up the handler's capacity.  This happens
+	// before replays are checked so that rejected requests can be retried.
+	inv, ok := h.admitInvocation(w, r)
+	if !ok {
+		return nil
+	}
+	// The invocation finishes once the function returns.  When streaming, the
+	// function runs in a goroutine which finishes the invocation itself, as it
+	// may outlive the request.
+	finish := true
+	defer func() {
+		if finish {
+			h.finishInvocation(inv)
+		}
+	}()
+	r = r.WithContext(context.WithValue(r.Context(), invocationCtxKey, inv))
+
+	if err := h.rejectReplay(r.Context(), r, sig); err != nil {
+		return err
+	}
+
 	fnID := r.URL.Query().Get("fnId")
 
 	request := &sdkrequest.Request{}
@@ -815,17 +1058,7 @@ func (h *handler) invoke(w http.ResponseWriter, r *http.Request) error {
 		_ = 0 // no-op to avoid linter error
 	}
 
-	h.l.RLock()
-	var fn ServableFunction
-	for _, f := range h.funcs {
-		isOldFormat := f.ID() == fnID // Only include function slug
-		if f.FullyQualifiedID() == fnID || isOldFormat {
-			fn = f
-			break
-		}
-	}
-	h.l.RUnlock()
-
+	fn := h.function(fnID)
 	if fn == nil {
 		return fmt.Errorf("%w: %s", errFunctionMissing, fnID)
 	}
@@ -851,24 +1084,40 @@ func (h *handler) invoke(w http.ResponseWriter, r *http.Request) error {
 		invokeErr error
 	)
 
-	if h.UseStreaming {
+	ctx := r.Context()
+	var sse *sseWriter
+	if h.UseServerSentEvents && acceptsEventStream(r) {
+		sse = newSSEWriter(w)
+		defer sse.close()
+		ctx = withOpStream(ctx, func(op sdkrequest.GeneratorOpcode) {
+			if err := sse.event(sseEventStep, op.ID, op); err != nil {
+				l.Warn("error streaming step", "error", err, "step_id", op.ID)
+			}
+		})
+	}
+
+	if sse != nil || h.UseStreaming {
 		type invokeResult struct {
 			resp any
 			ops  []sdkrequest.GeneratorOpcode
 			err  error
 		}
 
-		w.WriteHeader(201)
+		if sse == nil {
+			w.WriteHeader(201)
+		}
 
 		results := make(chan invokeResult, 1)
+		finish = false
 		go func() {
+			defer h.finishInvocation(inv)
 			resp, ops, err := invoke(
-				r.Context(),
+				ctx,
 				h.client,
 				mw,
 				fn,
-				h.GetSigningKey(),
-				h.GetSigningKeyFallback(),
+				keys.Current,
+				keys.Fallback,
 				request,
 				stepID,
 			)
@@ -884,10 +1133,16 @@ func (h *handler) invoke(w http.ResponseWriter, r *http.Request) error {
 			case result := <-results:
 				resp, ops, invokeErr = result.resp, result.ops, result.err
 				done = true
-			case <-r.Context().Done():
-				invokeErr = r.Context().Err()
+			case <-ctx.Done():
+				invokeErr = ctx.Err()
 				done = true
 			case <-ticker.C:
+				if sse != nil {
+					if err := sse.event(sseEventHeartbeat, "", struct{}{}); err != nil {
+						l.Warn("error streaming heartbeat", "error", err)
+					}
+					continue
+				}
 				_, _ = w.Write([]byte(" "))
 				if flusher, ok := w.(http.Flusher); ok {
 					flusher.Flush()
@@ -896,17 +1151,19 @@ func (h *handler) invoke(w http.ResponseWriter, r *http.Request) error {
 		}
 	} else {
 		resp, ops, invokeErr = invoke(
-			r.Context(),
+			ctx,
 			h.client,
 			mw,
 			fn,
-			h.GetSigningKey(),
-			h.GetSigningKeyFallback(),
+			keys.Current,
+			keys.Fallback,
 			request,
 			stepID,
 		)
 	}
 
+	h.recordInvocation(fn.FullyQualifiedID(), ops, invokeErr)
+
 	// NOTE: When triggering step errors, we should have an OpcodeStepError
 	// within ops alongside an error.  We can safely ignore that error, as it's
 	// only used for checking whether the step used a NoRetryError or RetryAtError
@@ -940,26 +1197,15 @@ func (h *handler) invoke(w http.ResponseWriter, r *http.Request) error {
 		noRetry = true
 	}
 
-	if h.UseStreaming {
+	if sse != nil || h.UseStreaming {
 		if invokeErr != nil {
-			// TODO: Add retry-at.
-			return json.NewEncoder(w).Encode(StreamResponse{
-				StatusCode: 500,
-				Body:       fmt.Sprintf("error calling function: %s", invokeErr.Error()),
-				NoRetry:    noRetry,
-				RetryAt:    retryAt,
-			})
+			l.Error("error calling function", "error", invokeErr)
 		}
-		if len(ops) > 0 {
-			return json.NewEncoder(w).Encode(StreamResponse{
-				StatusCode: 206,
-				Body:       ops,
-			})
+		res := newStreamResponse(resp, ops, invokeErr, noRetry, retryAt)
+		if sse != nil {
+			return sse.event(sseEventResult, "", res)
 		}
-		return json.NewEncoder(w).Encode(StreamResponse{
-			StatusCode: 200,
-			Body:       resp,
-		})
+		return json.NewEncoder(w).Encode(res)
 	}
 
 	// These may be added even for 2xx codes with step errors.
@@ -979,12 +1225,11 @@ func (h *handler) invoke(w http.ResponseWriter, r *http.Request) error {
 		// Return the function opcode returned here so that we can re-invoke this
 		// function and manage state appropriately.  Any opcode here takes precedence
 		// over function return values as the function has not yet finished.
-		w.WriteHeader(206)
-		return json.NewEncoder(w).Encode(ops)
+		return writeJSON(w, r, 206, ops)
 	}
 
 	// Return the function response.
-	return json.NewEncoder(w).Encode(resp)
+	return writeJSON(w, r, 200, resp)
 }
 
 type insecureInspection struct {
@@ -1014,9 +1259,13 @@ type secureInspection struct {
 	ServePath              *string            `json:"serve_path"`
 	SigningKeyFallbackHash *string            `json:"signing_key_fallback_hash"`
 	SigningKeyHash         *string            `json:"signing_key_hash"`
+
+	// Functions is only included if DetailedInspection is enabled.
+	Functions []functionInspection `json:"functions,omitempty"`
 }
 
 func (h *handler) createInsecureInspection(
+	ctx context.Context,
 	authenticationSucceeded *bool,
 ) (*insecureInspection, error) {
 	mode := "cloud"
@@ -1024,18 +1273,19 @@ func (h *handler) createInsecureInspection(
 		mode = "dev"
 	}
 
+	keys := h.signingKeys(ctx)
 	return &insecureInspection{
 		AuthenticationSucceeded: authenticationSucceeded,
 		FunctionCount:           len(h.funcs),
 		HasEventKey:             os.Getenv("INNGEST_EVENT_KEY") != "",
-		HasSigningKey:           h.GetSigningKey() != "",
-		HasSigningKeyFallback:   h.GetSigningKeyFallback() != "",
+		HasSigningKey:           keys.Current != "",
+		HasSigningKeyFallback:   keys.Fallback != "",
 		Mode:                    mode,
 		SchemaVersion:           "2024-05-24",
 	}, nil
 }
 
-func (h *handler) createSecureInspection() (*secureInspection, error) {
+func (h *handler) createSecureInspection(ctx context.Context) (*secureInspection, error) {
 	apiOrigin := defaultAPIOrigin
 	eventAPIOrigin := defaultEventAPIOrigin
 	if h.isDev() {
@@ -1049,9 +1299,10 @@ func (h *handler) createSecureInspection() (*secureInspection, error) {
 		eventKeyHash = &hash
 	}
 
+	keys := h.signingKeys(ctx)
 	var signingKeyHash *string
-	if h.GetSigningKey() != "" {
-		key, err := hashedSigningKey([]byte(h.GetSigningKey()))
+	if keys.Current != "" {
+		key, err := hashedSigningKey([]byte(keys.Current))
 		if err != nil {
 			return nil, fmt.Errorf("error hashing signing key: %w", err)
 		}
@@ -1060,8 +1311,8 @@ func (h *handler) createSecureInspection() (*secureInspection, error) {
 	}
 
 	var signingKeyFallbackHash *string
-	if h.GetSigningKeyFallback() != "" {
-		key, err := hashedSigningKey([]byte(h.GetSigningKeyFallback()))
+	if keys.Fallback != "" {
+		key, err := hashedSigningKey([]byte(keys.Fallback))
 		if err != nil {
 			return nil, fmt.Errorf("error hashing signing key fallback: %w", err)
 		}
@@ -1078,7 +1329,7 @@ func (h *handler) createSecureInspection() (*secureInspection, error) {
 	}
 
 	authenticationSucceeded = true
-	insecureInspection, err := h.createInsecureInspection(&authenticationSucceeded)
+	insecureInspection, err := h.createInsecureInspection(ctx, &authenticationSucceeded)
 	if err != nil {
 		return nil, fmt.Errorf("error creating inspection: %w", err)
 	}
@@ -1087,7 +1338,7 @@ func (h *handler) createSecureInspection() (*secureInspection, error) {
 		insecureInspection:     *insecureInspection,
 		APIOrigin:              apiOrigin,
 		AppID:                  h.appName,
-		Capabilities:           capabilities,
+		Capabilities:           h.capabilities(),
 		Env:                    env,
 		EventAPIOrigin:         eventAPIOrigin,
 		EventKeyHash:           eventKeyHash,
@@ -1107,19 +1358,25 @@ func (h *handler) inspect(w http.ResponseWriter, r *http.Request) error {
 
 	sig := r.Header.Get(HeaderKeySignature)
 	if sig != "" {
+		keys := h.signingKeys(r.Context())
 		valid, _, _ := ValidateRequestSignature(
 			r.Context(),
 			sig,
-			h.GetSigningKey(),
-			h.GetSigningKeyFallback(),
+			keys.Current,
+			keys.Fallback,
 			[]byte{},
 			h.isDev(),
 		)
 		if valid {
-			inspection, err := h.createSecureInspection()
+			inspection, err := h.createSecureInspection(r.Context())
 			if err != nil {
 				return err
 			}
+			if h.DetailedInspection {
+				if inspection.Functions, err = h.inspectFunctions(r); err != nil {
+					return err
+				}
+			}
 
 			w.Header().Set(HeaderKeyContentType, "application/json")
 			return json.NewEncoder(w).Encode(inspection)
@@ -1136,7 +1393,7 @@ func (h *handler) inspect(w http.ResponseWriter, r *http.Request) error {
 		authenticationSucceeded = &val
 	}
 
-	inspection, err := h.createInsecureInspection(authenticationSucceeded)
+	inspection, err := h.createInsecureInspection(r.Context(), authenticationSucceeded)
 	if err != nil {
 		return fmt.Errorf("error creating inspection: %w", err)
 	}
@@ -1145,6 +1402,27 @@ func (h *handler) inspect(w http.ResponseWriter, r *http.Request) error {
 	return json.NewEncoder(w).Encode(inspection)
 }
 
+// rejectReplay returns an error if the request's signature has already been
+// used.  This must only be called once the signature has been validated.
+func (h *handler) rejectReplay(ctx context.Context, r *http.Request, sig string) error {
+	err := CheckReplayedSignature(ctx, h.ReplayStore, sig)
+	if err == nil {
+		return nil
+	}
+
+	l := h.Logger.With("request_id", r.Header.Get(HeaderKeyRequestID))
+	if errors.Is(err, ErrReplayedSignature) {
+		l.Warn("rejecting replayed request", "path", r.URL.Path)
+		return errUnauthorized
+	}
+	l.Error("error checking request for replay", "error", err)
+	return publicerr.Error{
+		Err:     err,
+		Message: "error checking request signature",
+		Status:  500,
+	}
+}
+
 type trustProbeResponse struct {
 	Error *string `json:"error,omitempty"`
 }
@@ -1169,7 +1447,7 @@ func (h *handler) trust(
 	if max == 0 {
 		max = DefaultMaxBodySize
 	}
-	byt, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(max)))
+	byt, err := readBody(w, r, max)
 	if err != nil {
 		h.Logger.Error("error decoding function request", "error", err)
 		return publicerr.Error{
@@ -1178,11 +1456,12 @@ func (h *handler) trust(
 		}
 	}
 
+	keys := h.signingKeys(ctx)
 	valid, key, err := ValidateRequestSignature(
 		ctx,
 		r.Header.Get("X-Inngest-Signature"),
-		h.GetSigningKey(),
-		h.GetSigningKeyFallback(),
+		keys.Current,
+		keys.Fallback,
 		byt,
 		h.isDev(),
 	)
@@ -1192,6 +1471,9 @@ func (h *handler) trust(
 	if !valid {
 		return errUnauthorized
 	}
+	if err := h.rejectReplay(ctx, r, sig); err != nil {
+		return err
+	}
 
 	byt, err = json.Marshal(trustProbeResponse{})
 	if err != nil {
@@ -1216,6 +1498,8 @@ func (h *handler) trust(
 	return nil
 }
 
+// StreamResponse is the final result of a streamed invocation, standing in for
+// the status, headers and body of a non-streamed response.
 type StreamResponse struct {
 	StatusCode int               `json:"status"`
 	Body       any               `json:"body"`
@@ -1224,6 +1508,41 @@ type StreamResponse struct {
 	Headers    map[string]string `json:"headers"`
 }
 
+// newStreamResponse creates the StreamResponse for an invocation's result.  As
+// with non-streamed responses, retry headers are set for step errors as well as
+// function errors.
+func newStreamResponse(
+	resp any,
+	ops []sdkrequest.GeneratorOpcode,
+	invokeErr error,
+	noRetry bool,
+	retryAt *time.Time,
+) StreamResponse {
+	res := StreamResponse{
+		StatusCode: 200,
+		Body:       resp,
+		RetryAt:    retryAt,
+		NoRetry:    noRetry,
+		Headers:    map[string]string{},
+	}
+	if noRetry {
+		res.Headers[HeaderKeyNoRetry] = "true"
+	}
+	if retryAt != nil {
+		res.Headers[HeaderKeyRetryAfter] = retryAt.Format(time.RFC3339)
+	}
+
+	switch {
+	case invokeErr != nil:
+		res.StatusCode = 500
+		res.Body = fmt.Sprintf("error calling function: %s", invokeErr.Error())
+	case len(ops) > 0:
+		res.StatusCode = 206
+		res.Body = ops
+	}
+	return res
+}
+
 // invoke calls a given servable function with the specified input event.  The input event must
 // be fully typed.
 func invoke(
@@ -1260,6 +1579,7 @@ func invoke(
 		Fn:                 sf,
 		Middleware:         mw,
 		Cancel:             cancel,
+		OnOp:               opStream(ctx),
 		Request:            input,
 		SigningKey:         signingKey,
 		SigningKeyFallback: signingKeyFallback,
@@ -1267,6 +1587,9 @@ func invoke(
 		APIBaseURL:         env.APIServerURL(client.Options().APIBaseURL),
 	})
 	defer mgr.CloseCheckpointer()
+	if inv, ok := ctx.Value(invocationCtxKey).(*invocation); ok {
+		inv.start(ctx, input.CallCtx.RunID, mgr)
+	}
 	fCtx = sdkrequest.SetManager(fCtx, mgr)
 
 	// Create a new Input type.  We don't know ahead of time the type signature as
@@ -1326,7 +1649,7 @@ func invoke(
 				}
 
 				panicStack := string(debug.Stack())
-				panicErr = fmt.Errorf("function panicked: %v.  stack:\n%s", r, panicStack)
+				panicErr = fmt.Errorf("%w: %v.  stack:\n%s", errFunctionPanicked, r, panicStack)
 
 				mw.AfterExecution(ctx, callCtx, nil, nil)
 				mw.OnPanic(ctx, callCtx, r, panicStack)
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -22,6 +23,8 @@ import (
 	"github.com/inngest/inngestgo/internal/logger"
 	"github.com/inngest/inngestgo/internal/sdkrequest"
 	"github.com/inngest/inngestgo/middleware"
+	"github.com/inngest/inngestgo/pkg/replay"
+	"github.com/inngest/inngestgo/pkg/signingkey"
 	"github.com/inngest/inngestgo/step"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -1064,6 +1067,91 @@ func assertOnlySDKHandledInngestHeader(t *testing.T, resp *http.Response) {
 	r.ElementsMatch([]string{strings.ToLower(HeaderKeySDKHandled)}, inngestHeaders)
 }
 
+func TestSigningKeyProvider(t *testing.T) {
+	setEnvVars(t)
+	r := require.New(t)
+	c, err := NewClient(ClientOpts{AppID: "test-signing-key-provider"})
+	r.NoError(err)
+
+	rotatedKey := "signkey-test-abcdef01"
+	keys := signingkey.Keys{Current: testKey}
+	h := newHandler(c, handlerOpts{
+		SigningKeyProvider: signingkey.ProviderFunc(func(ctx context.Context) (signingkey.Keys, error) {
+			// Keys are loaded with the request's context.
+			r.NotNil(ctx.Value(http.ServerContextKey))
+			return keys, nil
+		}),
+	})
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	reqBodyByt, _ := json.Marshal(inBandSynchronizeRequest{
+		URL: "http://test.local",
+	})
+	sync := func(key string) int {
+		sig, _ := Sign(context.Background(), time.Now(), []byte(key), reqBodyByt)
+		req, err := http.NewRequest(http.MethodPut, server.URL, bytes.NewReader(reqBodyByt))
+		r.NoError(err)
+		req.Header.Set("x-inngest-signature", sig)
+		resp, err := http.DefaultClient.Do(req)
+		r.NoError(err)
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	r.Equal(http.StatusOK, sync(testKey))
+	r.Equal(http.StatusUnauthorized, sync(rotatedKey))
+
+	// Rotated keys are used without recreating the handler, with the previous
+	// key accepted as the fallback.
+	keys = signingkey.Keys{Current: rotatedKey, Fallback: testKey}
+	r.Equal(http.StatusOK, sync(rotatedKey))
+	r.Equal(http.StatusOK, sync(testKey))
+
+	keys = signingkey.Keys{Current: rotatedKey}
+	r.Equal(http.StatusUnauthorized, sync(testKey))
+}
+
+func TestReplayProtection(t *testing.T) {
+	setEnvVars(t)
+	r := require.New(t)
+
+	var logs bytes.Buffer
+	c, err := NewClient(ClientOpts{AppID: "test-replay-protection"})
+	r.NoError(err)
+
+	h := newHandler(c, handlerOpts{
+		Logger:      slog.New(slog.NewTextHandler(&logs, nil)),
+		ReplayStore: replay.NewLRU(0),
+	})
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	body := []byte(`{}`)
+	probe := func(sig string) int {
+		req, err := http.NewRequest(http.MethodPost, server.URL+"?probe=trust", bytes.NewReader(body))
+		r.NoError(err)
+		req.Header.Set(HeaderKeySignature, sig)
+		req.Header.Set(HeaderKeyRequestID, "req-1")
+		resp, err := http.DefaultClient.Do(req)
+		r.NoError(err)
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	sig, _ := Sign(context.Background(), time.Now(), []byte(testKey), body)
+	r.Equal(http.StatusOK, probe(sig))
+
+	// Replaying the same signed request is rejected.
+	r.Equal(http.StatusUnauthorized, probe(sig))
+	r.Contains(logs.String(), "rejecting replayed request")
+	r.Contains(logs.String(), "request_id=req-1")
+
+	// Newly signed requests are accepted.
+	sig, _ = Sign(context.Background(), time.Now().Add(time.Second), []byte(testKey), body)
+	r.Equal(http.StatusOK, probe(sig))
+}
+
 func TestInBandSync(t *testing.T) {
 	setEnvVars(t)
 	r := require.New(t)
@@ -1130,6 +1218,7 @@ func TestInBandSync(t *testing.T) {
 						},
 					},
 					Triggers: []ifn.Trigger{EventTrigger("my-event", nil)},
+					Schema:   ifn.GetSchema(fn),
 				}},
 				Inspection: map[string]any{
 					"api_origin":               "https://api.inngest.com",
@@ -1374,6 +1463,7 @@ func TestInBandSync(t *testing.T) {
 						},
 					},
 					Triggers: []ifn.Trigger{EventTrigger("my-event", nil)},
+					Schema:   ifn.GetSchema(fn),
 				}},
 				Inspection: map[string]any{
 					"api_origin":               "https://api.inngest.com",
@@ -1588,3 +1678,136 @@ func marshalRequest(t *testing.T, r *sdkrequest.Request) []byte {
 func toPtr[T any](v T) *T {
 	return &v
 }
+
+func TestServeLoadShedding(t *testing.T) {
+	r := require.New(t)
+
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	c, err := NewClient(ClientOpts{AppID: "load-shedding", Dev: BoolPtr(true)})
+	r.NoError(err)
+	_, err = CreateFunction(
+		c,
+		FunctionOpts{ID: "fn"},
+		EventTrigger("test/event", nil),
+		func(ctx context.Context, input Input[any]) (any, error) {
+			close(started)
+			<-unblock
+			return nil, nil
+		},
+	)
+	r.NoError(err)
+
+	invoke := func(server *httptest.Server) *http.Response {
+		body := marshalRequest(t, createRequest(t, Event{Name: "test/event"}))
+		resp, err := http.Post(server.URL+"?fnId=fn", "application/json", bytes.NewReader(body))
+		r.NoError(err)
+		_ = resp.Body.Close()
+		return resp
+	}
+
+	t.Run("concurrency", func(t *testing.T) {
+		server := httptest.NewServer(c.ServeWithOpts(ServeOpts{
+			MaxConcurrentInvocations: 1,
+			OverloadRetryAfter:       1500 * time.Millisecond,
+		}))
+		defer server.Close()
+
+		done := make(chan *http.Response)
+		go func() { done <- invoke(server) }()
+		<-started
+
+		resp := invoke(server)
+		require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		require.Equal(t, "2", resp.Header.Get(HeaderKeyRetryAfter))
+
+		close(unblock)
+		require.Equal(t, http.StatusOK, (<-done).StatusCode)
+	})
+
+	t.Run("memory", func(t *testing.T) {
+		server := httptest.NewServer(c.ServeWithOpts(ServeOpts{MaxHeapBytes: 1}))
+		defer server.Close()
+
+		resp := invoke(server)
+		require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		require.Equal(t, "5", resp.Header.Get(HeaderKeyRetryAfter))
+	})
+
+	t.Run("streaming disconnect", func(t *testing.T) {
+		r := require.New(t)
+
+		var once sync.Once
+		started := make(chan struct{})
+		unblock := make(chan struct{})
+		streaming, err := NewClient(ClientOpts{AppID: "load-shedding-streaming", Dev: BoolPtr(true), UseStreaming: true})
+		r.NoError(err)
+		_, err = CreateFunction(
+			streaming,
+			FunctionOpts{ID: "fn"},
+			EventTrigger("test/event", nil),
+			func(ctx context.Context, input Input[any]) (any, error) {
+				once.Do(func() { close(started) })
+				<-unblock
+				return nil, nil
+			},
+		)
+		r.NoError(err)
+
+		h := streaming.ServeWithOpts(ServeOpts{MaxConcurrentInvocations: 1})
+		returned := make(chan struct{}, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			h.ServeHTTP(w, req)
+			returned <- struct{}{}
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		body := marshalRequest(t, createRequest(t, Event{Name: "test/event"}))
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"?fnId=fn", bytes.NewReader(body))
+		r.NoError(err)
+		go func() {
+			if resp, err := http.DefaultClient.Do(req); err == nil {
+				_ = resp.Body.Close()
+			}
+		}()
+		<-started
+
+		// The function is still running after the client disconnects, so its
+		// slot is held until it returns.
+		cancel()
+		<-returned
+		r.Equal(http.StatusServiceUnavailable, invoke(server).StatusCode)
+		<-returned
+
+		close(unblock)
+		r.Eventually(func() bool {
+			unblocked := invoke(server).StatusCode
+			<-returned
+			return unblocked == http.StatusCreated
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		setEnvVars(t)
+		cloud, err := NewClient(ClientOpts{AppID: "load-shedding-cloud", Dev: BoolPtr(false)})
+		require.NoError(t, err)
+		_, err = CreateFunction(
+			cloud,
+			FunctionOpts{ID: "fn"},
+			EventTrigger("test/event", nil),
+			func(ctx context.Context, input Input[any]) (any, error) {
+				return nil, nil
+			},
+		)
+		require.NoError(t, err)
+
+		// Overload state isn't exposed to requests without a valid signature.
+		server := httptest.NewServer(cloud.ServeWithOpts(ServeOpts{MaxHeapBytes: 1}))
+		defer server.Close()
+
+		resp := invoke(server)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		require.Empty(t, resp.Header.Get(HeaderKeyRetryAfter))
+	})
+}
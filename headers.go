@@ -6,6 +6,7 @@ import (
 )
 
 const (
+	HeaderKeyAccept             = "Accept"
 	HeaderKeyAuthorization      = "Authorization"
 	HeaderKeyContentType        = "Content-Type"
 	HeaderKeyEnv                = "X-Inngest-Env"
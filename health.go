@@ -0,0 +1,120 @@
+package inngestgo
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/inngest/inngestgo/connect"
+)
+
+// HealthStatus reports whether a client is ready to serve functions.
+type HealthStatus struct {
+	// Ready is true if the client is ready to serve functions: it isn't
+	// draining, has a signing key (or is in dev mode) and, if connected via
+	// Connect, has an active connection.
+	Ready bool `json:"ready"`
+	// Draining is true once Drain has been called.
+	Draining bool `json:"draining"`
+	// HasSigningKey is true if a signing key is configured.
+	HasSigningKey bool `json:"has_signing_key"`
+	// LastSync is the result of the most recent sync, or nil if the app hasn't
+	// synced since starting.  This doesn't affect readiness, as in-band syncs
+	// require the app to be reachable.
+	LastSync *SyncStatus `json:"last_sync,omitempty"`
+	// ConnectionState is the state of the Connect connection, or nil if the
+	// client isn't connected via Connect.
+	ConnectionState *connect.ConnectionState `json:"connection_state,omitempty"`
+}
+
+// SyncStatus is the result of a sync.
+type SyncStatus struct {
+	// Kind is the kind of sync, either SyncKindInBand or SyncKindOutOfBand.
+	Kind string `json:"kind"`
+	// At is when the sync finished.
+	At time.Time `json:"at"`
+	// Succeeded is true if the sync succeeded.
+	Succeeded bool `json:"succeeded"`
+	// Error is the error if the sync failed.
+	Error string `json:"error,omitempty"`
+}
+
+// HealthHandler returns an HTTP handler for liveness and readiness probes,
+// which are cheaper than the serve handler's introspection.  Requests to paths
+// ending in "/readyz" return the client's HealthStatus, with a 503 status if the
+// client isn't ready.  All other paths respond with a 200 status while the
+// process is alive:
+//
+//	health := client.(inngestgo.HealthChecker).HealthHandler()
+//	mux.Handle("/healthz", health)
+//	mux.Handle("/readyz", health)
+func (a apiClient) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.h.startSync()
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set(HeaderKeyContentType, "application/json")
+		if !strings.HasSuffix(r.URL.Path, "/readyz") {
+			_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+			return
+		}
+
+		status := a.h.health()
+		if !status.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(status)
+	})
+}
+
+func (h *handler) health() HealthStatus {
+	h.invocations.l.Lock()
+	draining := h.invocations.draining
+	h.invocations.l.Unlock()
+
+	status := HealthStatus{
+		Draining:      draining,
+		HasSigningKey: h.GetSigningKey() != "",
+	}
+
+	h.statusL.Lock()
+	status.LastSync = h.lastSync
+	conn := h.conn
+	h.statusL.Unlock()
+
+	ready := !draining && (status.HasSigningKey || h.isDev())
+	if conn != nil {
+		state := conn.State()
+		status.ConnectionState = &state
+		ready = ready && state == connect.ConnectionStateActive
+	}
+	status.Ready = ready
+	return status
+}
+
+// recordSync records the result of a sync for readiness checks.
+func (h *handler) recordSync(kind string, err error) {
+	status := &SyncStatus{
+		Kind:      kind,
+		At:        time.Now(),
+		Succeeded: err == nil,
+	}
+	if err != nil {
+		status.Error = err.Error()
+	}
+
+	h.statusL.Lock()
+	defer h.statusL.Unlock()
+	h.lastSync = status
+}
+
+// setConnection records the client's Connect connection for readiness checks.
+func (h *handler) setConnection(conn connect.WorkerConnection) {
+	h.statusL.Lock()
+	defer h.statusL.Unlock()
+	h.conn = conn
+}
@@ -0,0 +1,100 @@
+package inngestgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/inngest/inngestgo/connect"
+	"github.com/stretchr/testify/require"
+)
+
+type testConnection struct {
+	state connect.ConnectionState
+}
+
+func (c testConnection) State() connect.ConnectionState { return c.state }
+
+func (c testConnection) Close() error { return nil }
+
+func TestHealthHandler(t *testing.T) {
+	t.Setenv("INNGEST_SIGNING_KEY", "")
+	t.Setenv("INNGEST_SIGNING_KEY_FALLBACK", "")
+
+	check := func(t *testing.T, c Client, path string) (int, HealthStatus) {
+		rec := httptest.NewRecorder()
+		c.(HealthChecker).HealthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		var status HealthStatus
+		if path == "/readyz" {
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&status))
+		}
+		return rec.Code, status
+	}
+
+	t.Run("liveness", func(t *testing.T) {
+		c, err := NewClient(ClientOpts{AppID: "health", Dev: BoolPtr(false)})
+		require.NoError(t, err)
+
+		code, _ := check(t, c, "/healthz")
+		require.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("missing signing key", func(t *testing.T) {
+		c, err := NewClient(ClientOpts{AppID: "health", Dev: BoolPtr(false)})
+		require.NoError(t, err)
+
+		code, status := check(t, c, "/readyz")
+		require.Equal(t, http.StatusServiceUnavailable, code)
+		require.False(t, status.Ready)
+		require.False(t, status.HasSigningKey)
+	})
+
+	t.Run("sync and drain", func(t *testing.T) {
+		r := require.New(t)
+		c, err := NewClient(ClientOpts{
+			AppID:      "health",
+			Dev:        BoolPtr(false),
+			SigningKey: StrPtr(testKey),
+		})
+		r.NoError(err)
+		h := c.(*apiClient).h
+
+		code, status := check(t, c, "/readyz")
+		r.Equal(http.StatusOK, code)
+		r.Equal(HealthStatus{Ready: true, HasSigningKey: true}, status)
+
+		// Failed syncs are reported without affecting readiness.
+		h.recordSync(SyncKindOutOfBand, errors.New("registration failed"))
+		code, status = check(t, c, "/readyz")
+		r.Equal(http.StatusOK, code)
+		r.NotNil(status.LastSync)
+		r.False(status.LastSync.Succeeded)
+		r.Equal("registration failed", status.LastSync.Error)
+
+		_, err = c.(Drainer).Drain(context.Background())
+		r.NoError(err)
+		code, status = check(t, c, "/readyz")
+		r.Equal(http.StatusServiceUnavailable, code)
+		r.True(status.Draining)
+	})
+
+	t.Run("connection state", func(t *testing.T) {
+		r := require.New(t)
+		c, err := NewClient(ClientOpts{AppID: "health", Dev: BoolPtr(true)})
+		r.NoError(err)
+		h := c.(*apiClient).h
+
+		h.setConnection(testConnection{state: connect.ConnectionStateReconnecting})
+		code, status := check(t, c, "/readyz")
+		r.Equal(http.StatusServiceUnavailable, code)
+		r.Equal(connect.ConnectionStateReconnecting, *status.ConnectionState)
+
+		h.setConnection(testConnection{state: connect.ConnectionStateActive})
+		code, _ = check(t, c, "/readyz")
+		r.Equal(http.StatusOK, code)
+	})
+}
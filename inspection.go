@@ -0,0 +1,195 @@
+package inngestgo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/inngest/inngest/pkg/enums"
+	"github.com/inngest/inngestgo/internal/fn"
+	"github.com/inngest/inngestgo/internal/sdkrequest"
+	"github.com/inngest/inngestgo/internal/types"
+	"github.com/inngest/inngestgo/pkg/httputil"
+)
+
+// errFunctionPanicked wraps the error returned when a function panics.
+var errFunctionPanicked = errors.New("function panicked")
+
+// functionInspection describes a function in detailed inspection responses.
+type functionInspection struct {
+	ID string `json:"id"`
+	// ConfigHash is the hash of Config without its runtime URLs, which changes
+	// whenever the function's synced config changes, regardless of the host
+	// that the inspection request reached.
+	ConfigHash string `json:"config_hash"`
+	// Fingerprint identifies the function's code and config.  See
+	// ClientOpts.FingerprintFunctions.
+	Fingerprint string        `json:"fingerprint"`
+	Config      fn.SyncConfig `json:"config"`
+	Stats       functionStats `json:"stats"`
+}
+
+// functionStats counts a function's invocations since the process started.
+type functionStats struct {
+	// Successes counts invocations which returned a result or ran a step
+	// without error.
+	Successes int64 `json:"successes"`
+	// StepErrors counts invocations in which a step errored.
+	StepErrors int64 `json:"step_errors"`
+	// Errors counts invocations in which the function returned an error.
+	Errors int64 `json:"errors"`
+	// Panics counts invocations in which the function panicked.
+	Panics int64 `json:"panics"`
+	// LastInvokedAt is when the function was last invoked.
+	LastInvokedAt *time.Time `json:"last_invoked_at"`
+}
+
+// recordInvocation counts an invocation's outcome in the function's stats.
+func (h *handler) recordInvocation(fnID string, ops []sdkrequest.GeneratorOpcode, err error) {
+	h.statusL.Lock()
+	defer h.statusL.Unlock()
+
+	if h.stats == nil {
+		h.stats = map[string]*functionStats{}
+	}
+	stats, ok := h.stats[fnID]
+	if !ok {
+		stats = &functionStats{}
+		h.stats[fnID] = stats
+	}
+
+	now := time.Now()
+	stats.LastInvokedAt = &now
+	switch {
+	case errors.Is(err, errFunctionPanicked):
+		stats.Panics++
+	case len(ops) == 1 && (ops[0].Op == enums.OpcodeStepError || ops[0].Op == enums.OpcodeStepFailed):
+		stats.StepErrors++
+	case err != nil:
+		stats.Errors++
+	default:
+		stats.Successes++
+	}
+}
+
+// inspectFunctions describes each function as it would be synced from the
+// request's URL.
+func (h *handler) inspectFunctions(r *http.Request) ([]functionInspection, error) {
+	appURL, err := h.appURL(r)
+	if err != nil {
+		return nil, err
+	}
+
+	h.l.RLock()
+	defer h.l.RUnlock()
+	configs, err := createFunctionConfigs(h.appName, h.funcs, *appURL, false)
+	if err != nil {
+		return nil, fmt.Errorf("error creating function configs: %w", err)
+	}
+
+	h.statusL.Lock()
+	defer h.statusL.Unlock()
+
+	hashed := withoutRuntimeURLs(configs)
+	functions := make([]functionInspection, len(configs))
+	for i, config := range configs {
+		hash, err := configHash(hashed[i])
+		if err != nil {
+			return nil, err
+		}
+		fingerprint, err := functionFingerprint(h.funcs[i])
+		if err != nil {
+			return nil, err
+		}
+
+		functions[i] = functionInspection{
+			ID:          config.Slug,
+			ConfigHash:  hash,
+			Fingerprint: fingerprint,
+			Config:      config,
+		}
+		if stats, ok := h.stats[config.Slug]; ok {
+			functions[i].Stats = *stats
+		}
+	}
+	return functions, nil
+}
+
+// appURL returns the URL that the app is served at, based on the request's URL
+// and any overrides.
+func (h *handler) appURL(r *http.Request) (*url.URL, error) {
+	appURL, err := requestURL(r)
+	if err != nil {
+		return nil, err
+	}
+	appURL, err = overrideURL(appURL, h.handlerOpts)
+	if err != nil {
+		return nil, fmt.Errorf("error overriding request URL: %w", err)
+	}
+	return appURL, nil
+}
+
+// requestURL returns the URL that the request was made to.
+func requestURL(r *http.Request) (*url.URL, error) {
+	u, err := url.Parse(fmt.Sprintf(
+		"%s://%s%s?%s",
+		httputil.GetScheme(r),
+		r.Host,
+		r.URL.Path,
+		r.URL.RawQuery,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing request URL: %w", err)
+	}
+	return u, nil
+}
+
+// configHash returns a stable hash of the given config's JSON encoding.
+func configHash(config any) (string, error) {
+	byt, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("error hashing config: %w", err)
+	}
+	sum := sha256.Sum256(byt)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// syncHash returns a stable hash of an app's synced config, including the URL
+// that the app is registered with.  Functions are hashed without their runtime
+// URLs, which are derived from the app's URL, so that the hash only changes when
+// the app's config or URL changes.
+func syncHash(fns []fn.SyncConfig, appVersion string, appURL *url.URL, hOpts handlerOpts) (string, error) {
+	return configHash(struct {
+		Functions    []fn.SyncConfig    `json:"functions"`
+		AppVersion   string             `json:"app_version"`
+		Capabilities types.Capabilities `json:"capabilities"`
+		URL          string             `json:"url"`
+	}{withoutRuntimeURLs(fns), appVersion, hOpts.capabilities(), appURL.String()})
+}
+
+// withoutRuntimeURLs returns a copy of the given configs without the URLs that
+// each step is served from.
+func withoutRuntimeURLs(fns []fn.SyncConfig) []fn.SyncConfig {
+	result := make([]fn.SyncConfig, len(fns))
+	for i, config := range fns {
+		steps := make(map[string]fn.SDKStep, len(config.Steps))
+		for id, step := range config.Steps {
+			runtime := make(map[string]any, len(step.Runtime))
+			for k, v := range step.Runtime {
+				if k != "url" {
+					runtime[k] = v
+				}
+			}
+			step.Runtime = runtime
+			steps[id] = step
+		}
+		config.Steps = steps
+		result[i] = config
+	}
+	return result
+}
@@ -0,0 +1,97 @@
+package inngestgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	ifn "github.com/inngest/inngestgo/internal/fn"
+	"github.com/inngest/inngestgo/step"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetailedInspection(t *testing.T) {
+	setEnvVars(t)
+	r := require.New(t)
+
+	c, err := NewClient(ClientOpts{AppID: "inspection", Dev: BoolPtr(false)})
+	r.NoError(err)
+	fn, err := CreateFunction(
+		c,
+		FunctionOpts{ID: "fn"},
+		EventTrigger("test/event.a", nil),
+		func(ctx context.Context, input Input[EventAData]) (any, error) {
+			switch input.Event.Data.Foo {
+			case "panic":
+				panic("oops")
+			case "error":
+				return nil, errors.New("failed")
+			case "step error":
+				return step.Run(ctx, "step", func(ctx context.Context) (any, error) {
+					return nil, errors.New("step failed")
+				})
+			}
+			return "ok", nil
+		},
+	)
+	r.NoError(err)
+
+	server := httptest.NewServer(c.ServeWithOpts(ServeOpts{DetailedInspection: true}))
+	defer server.Close()
+
+	q := url.Values{}
+	q.Add("fnId", fn.FullyQualifiedID())
+	urlStr := fmt.Sprintf("%s?%s", server.URL, q.Encode())
+	for _, foo := range []string{"ok", "ok", "error", "panic", "step error"} {
+		resp := handlerPost(t, urlStr, createRequest(t, EventA{
+			Name: "test/event.a",
+			Data: EventAData{Foo: foo},
+		}))
+		_ = resp.Body.Close()
+	}
+
+	inspect := func(t *testing.T) secureInspection {
+		sig, _ := Sign(context.Background(), time.Now(), []byte(testKey), []byte{})
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set(HeaderKeySignature, sig)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var inspection secureInspection
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&inspection))
+		return inspection
+	}
+
+	inspection := inspect(t)
+	r.Len(inspection.Functions, 1)
+	details := inspection.Functions[0]
+	r.Equal(fn.FullyQualifiedID(), details.ID)
+	r.Equal(fn.FullyQualifiedID(), details.Config.Slug)
+
+	// The hash excludes the URL that the inspection request was made to.
+	hash, err := configHash(withoutRuntimeURLs([]ifn.SyncConfig{details.Config})[0])
+	r.NoError(err)
+	r.Equal(hash, details.ConfigHash)
+	r.Equal(details.ConfigHash, inspect(t).Functions[0].ConfigHash)
+
+	r.EqualValues(2, details.Stats.Successes)
+	r.EqualValues(1, details.Stats.Errors)
+	r.EqualValues(1, details.Stats.Panics)
+	r.EqualValues(1, details.Stats.StepErrors)
+	r.NotNil(details.Stats.LastInvokedAt)
+
+	// Functions aren't included unless enabled.
+	server.Config.Handler = c.Serve()
+	r.Empty(inspect(t).Functions)
+}
@@ -0,0 +1,65 @@
+// Package apiauth authenticates requests to the Inngest API using signing keys.
+package apiauth
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+)
+
+// Keys holds a primary and fallback signing key.  Requests are authenticated
+// using the primary key until it's rejected, after which the fallback key is
+// used.  This allows signing keys to be rotated without downtime.
+type Keys struct {
+	primary     string
+	fallback    string
+	useFallback atomic.Bool
+}
+
+// NewKeys returns Keys for the given primary and optional fallback signing key.
+func NewKeys(primary, fallback string) *Keys {
+	return &Keys{primary: primary, fallback: fallback}
+}
+
+// Current returns the signing key currently used to authenticate requests.
+func (k *Keys) Current() string {
+	if k.useFallback.Load() {
+		return k.fallback
+	}
+	return k.primary
+}
+
+// UsingFallback returns whether the fallback key is being used.
+func (k *Keys) UsingFallback() bool {
+	return k.useFallback.Load()
+}
+
+// SetUsingFallback sets whether the fallback key is used.
+func (k *Keys) SetUsingFallback(v bool) {
+	k.useFallback.Store(v)
+}
+
+// Do sends the request returned by newRequest, authenticated using the current
+// signing key, if any.  If the request is rejected with a 401 and the fallback
+// key isn't yet in use, Do switches to the fallback key and sends a new request.
+func (k *Keys) Do(client *http.Client, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	req, err := newRequest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if key := k.Current(); key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized && k.fallback != "" && !k.useFallback.Load() {
+		_ = resp.Body.Close()
+		k.useFallback.Store(true)
+		return k.Do(client, newRequest)
+	}
+	return resp, nil
+}
@@ -58,6 +58,10 @@ type Checkpointer interface {
 	// need to be saved.
 	WithStep(ctx context.Context, step opcode.Step, cb Callback)
 
+	// Flush immediately checkpoints any buffered steps, rather than waiting for
+	// the batch interval.
+	Flush(ctx context.Context, cb Callback)
+
 	// Close cancels any pending background checkpoint timers and clears the
 	// buffer.  It should be called when the function invocation completes so
 	// that no checkpoint API call fires after the response has been sent.
@@ -122,6 +126,10 @@ func (c *checkpointer) WithStep(ctx context.Context, step opcode.Step, cb Callba
 	}
 }
 
+func (c *checkpointer) Flush(ctx context.Context, cb Callback) {
+	c.checkpoint(ctx, cb)
+}
+
 func (c *checkpointer) Close() {
 	select {
 	case <-c.done:
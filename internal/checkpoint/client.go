@@ -7,25 +7,22 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"sync/atomic"
 	"time"
+
+	"github.com/inngest/inngestgo/internal/apiauth"
 )
 
 type Client struct {
-	primaryKey  string
-	fallbackKey string
-	apiBaseURL  string
-	httpClient  *http.Client
-	useFallback *atomic.Bool
+	keys       *apiauth.Keys
+	apiBaseURL string
+	httpClient *http.Client
 }
 
 func NewClient(apiURL, primaryKey, fallbackKey string) *Client {
 	return &Client{
-		primaryKey:  primaryKey,
-		fallbackKey: fallbackKey,
-		apiBaseURL:  apiURL,
-		httpClient:  &http.Client{Timeout: 30 * time.Second},
-		useFallback: &atomic.Bool{},
+		keys:       apiauth.NewKeys(primaryKey, fallbackKey),
+		apiBaseURL: apiURL,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
 	}
 }
 
@@ -43,21 +40,21 @@ func (c *Client) do(ctx context.Context, req AsyncRequest) error {
 		return err
 	}
 
-	hr, err := http.NewRequest(
-		http.MethodPost,
-		c.checkpointURL(req.RunID),
-		bytes.NewBuffer(byt),
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	hr.Header.Set("Content-Type", "application/json")
-	hr.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.getCurrentSigningKey()))
-
-	resp, err := c.httpClient.Do(hr)
+	resp, err := c.keys.Do(c.httpClient, func() (*http.Request, error) {
+		hr, err := http.NewRequestWithContext(
+			ctx,
+			http.MethodPost,
+			c.checkpointURL(req.RunID),
+			bytes.NewBuffer(byt),
+		)
+		if err != nil {
+			return nil, err
+		}
+		hr.Header.Set("Content-Type", "application/json")
+		return hr, nil
+	})
 	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+		return err
 	}
 	defer func() {
 		_ = resp.Body.Close()
@@ -69,21 +66,8 @@ func (c *Client) do(ctx context.Context, req AsyncRequest) error {
 	}
 
 	if resp.StatusCode >= 400 {
-		// If we get a 401 and have a fallback key, try switching to it
-		if resp.StatusCode == 401 && c.fallbackKey != "" && !c.useFallback.Load() {
-			c.useFallback.Store(true)
-			// Retry the request with the fallback key
-			return c.do(ctx, req)
-		}
 		return fmt.Errorf("error checkpointing (%d): %s", resp.StatusCode, byt)
 	}
 
 	return nil
 }
-
-func (c *Client) getCurrentSigningKey() string {
-	if c.useFallback.Load() {
-		return c.fallbackKey
-	}
-	return c.primaryKey
-}
@@ -46,7 +46,7 @@ func TestClient_Checkpoint_Success(t *testing.T) {
 
 	require.NoError(t, err)
 	assert.Equal(t, "Bearer primary-key", receivedAuthHeader)
-	assert.False(t, client.useFallback.Load())
+	assert.False(t, client.keys.UsingFallback())
 }
 
 func TestClient_Checkpoint_FallbackOnAuth(t *testing.T) {
@@ -96,7 +96,7 @@ func TestClient_Checkpoint_FallbackOnAuth(t *testing.T) {
 	assert.Len(t, receivedAuthHeaders, 2, "should have received 2 auth headers")
 	assert.Equal(t, "Bearer primary-key", receivedAuthHeaders[0])
 	assert.Equal(t, "Bearer fallback-key", receivedAuthHeaders[1])
-	assert.True(t, client.useFallback.Load(), "should have switched to fallback")
+	assert.True(t, client.keys.UsingFallback(), "should have switched to fallback")
 }
 
 func TestClient_Checkpoint_BothKeysFail(t *testing.T) {
@@ -128,7 +128,7 @@ func TestClient_Checkpoint_BothKeysFail(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "401")
 	assert.Equal(t, int32(2), callCount.Load(), "should have tried both keys")
-	assert.True(t, client.useFallback.Load(), "should have switched to fallback")
+	assert.True(t, client.keys.UsingFallback(), "should have switched to fallback")
 }
 
 func TestClient_Checkpoint_NoFallbackKey(t *testing.T) {
@@ -161,7 +161,7 @@ func TestClient_Checkpoint_NoFallbackKey(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "401")
 	assert.Equal(t, int32(1), callCount.Load(), "should have tried only once")
-	assert.False(t, client.useFallback.Load(), "should not have switched to fallback")
+	assert.False(t, client.keys.UsingFallback(), "should not have switched to fallback")
 }
 
 func TestClient_Checkpoint_Non401Error(t *testing.T) {
@@ -193,7 +193,7 @@ func TestClient_Checkpoint_Non401Error(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "500")
 	assert.Equal(t, int32(1), callCount.Load(), "should not retry on non-401 errors")
-	assert.False(t, client.useFallback.Load(), "should not have switched to fallback")
+	assert.False(t, client.keys.UsingFallback(), "should not have switched to fallback")
 }
 
 func TestClient_Checkpoint_FallbackAlreadyActive(t *testing.T) {
@@ -216,7 +216,7 @@ func TestClient_Checkpoint_FallbackAlreadyActive(t *testing.T) {
 	client.httpClient = server.Client()
 
 	// Manually activate fallback mode
-	client.useFallback.Store(true)
+	client.keys.SetUsingFallback(true)
 
 	req := AsyncRequest{
 		RunID:        "run-123",
@@ -1,6 +1,7 @@
 package fn
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,6 +10,7 @@ import (
 	"github.com/fatih/structs"
 	"github.com/inngest/inngest/pkg/enums"
 	"github.com/inngest/inngestgo/pkg/checkpoint"
+	"github.com/inngest/inngestgo/pkg/jsonschema"
 	"github.com/xhit/go-str2duration/v2"
 )
 
@@ -37,6 +39,23 @@ type ServableFunction interface {
 	Func() any
 }
 
+// SchemaProvider is optionally implemented by a ServableFunction to describe its
+// payloads when syncing.
+type SchemaProvider interface {
+	// Schema returns JSON Schemas describing the function's triggering event, or
+	// nil if the function's payloads are unknown.
+	Schema() *FunctionSchema
+}
+
+// GetSchema returns the function's schema if it implements SchemaProvider, or nil
+// otherwise.
+func GetSchema(f ServableFunction) *FunctionSchema {
+	if sp, ok := f.(SchemaProvider); ok {
+		return sp.Schema()
+	}
+	return nil
+}
+
 // FunctionOpts represents the options available to configure functions.  This includes
 // concurrency, retry, and flow control configuration.
 //
@@ -82,6 +101,50 @@ type FunctionOpts struct {
 	BatchEvents *EventBatchConfig
 	// Singleton ensures only one active function run per key.
 	Singleton *Singleton
+
+	// OnFailure is an optional handler called once a run of this function permanently
+	// fails, after all retries are exhausted.  Create this with inngestgo.FailureHandler.
+	//
+	// The handler is registered alongside the function as a separate function
+	// triggered by the "inngest/function.failed" event for this function.
+	OnFailure FailureHandler `json:"-"`
+
+	// OnCancel is an optional handler called once a run of this function is cancelled,
+	// eg. via Cancel events, singleton cancellation, or timeouts.  Create this with
+	// inngestgo.CancelHandler.
+	//
+	// The handler is registered alongside the function as a separate function
+	// triggered by the "inngest/function.cancelled" event for this function.
+	OnCancel CancelHandler `json:"-"`
+}
+
+// FailureHandler creates the companion function which is called once a run of a
+// function permanently fails.  See inngestgo.FailureHandler.
+type FailureHandler interface {
+	// FailureFunction returns the companion function for the given function.
+	FailureFunction(parent ServableFunction) ServableFunction
+}
+
+// RunStepsLoader loads the state of all completed steps within the given run,
+// keyed by hashed step ID.
+type RunStepsLoader func(ctx context.Context, runID string) (map[string]json.RawMessage, error)
+
+// CancelHandler creates the companion function which is called once a run of a
+// function is cancelled.  See inngestgo.CancelHandler.
+type CancelHandler interface {
+	// CancelFunction returns the companion function for the given function,
+	// which loads cancelled runs' steps using the given loader.
+	CancelFunction(parent ServableFunction, loadSteps RunStepsLoader) ServableFunction
+}
+
+// FunctionSchema describes a function's payloads as JSON Schema.
+//
+// Only the triggering event is described.  Step outputs aren't included: steps
+// are declared by calling step.Run while the function runs, so their output types
+// aren't known when the function is synced.
+type FunctionSchema struct {
+	// Event is the schema for the function's triggering event.
+	Event *jsonschema.Schema `json:"event,omitempty"`
 }
 
 func (f FunctionOpts) Validate() error {
@@ -25,6 +25,7 @@ func GetFnSyncConfig(fn ServableFunction) *SyncConfig {
 		Cancel:      config.Cancel,
 		Retries:     config.Retries,
 		Singleton:   config.Singleton,
+		Schema:      GetSchema(fn),
 	}
 }
 
@@ -85,6 +86,9 @@ type SyncConfig struct {
 	// the specified mode.
 	Singleton *Singleton
 
+	// Schema describes the function's triggering event as JSON Schema, allowing payloads to be viewed and validated.
+	Schema *FunctionSchema `json:"schema,omitempty"`
+
 	Steps map[string]SDKStep `json:"steps"`
 }
 
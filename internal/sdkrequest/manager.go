@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/inngest/inngest/pkg/enums"
@@ -84,6 +85,10 @@ type InvocationManager interface {
 	// CloseCheckpointer cancels any pending background checkpoint timers.
 	// It should be called when the function invocation completes.
 	CloseCheckpointer()
+	// Drain flushes any buffered checkpoints and returns control to the executor
+	// at the next step boundary rather than continuing execution, eg. when
+	// shutting down.
+	Drain(ctx context.Context)
 }
 
 type Opts struct {
@@ -102,6 +107,9 @@ type Opts struct {
 	// Cancel, when executed cancels the context that is passed to the
 	// sync or async function.
 	Cancel context.CancelFunc
+	// OnOp, if set, is called with each op as it's appended, eg. to stream
+	// progress to the executor before the invocation finishes.
+	OnOp func(op GeneratorOpcode)
 
 	// APIBaseURL, if set, is the URL to use for the Inngest API.
 	// Defaults to os.Getenv("INNGEST_DEV") if set as a URL (for development), and
@@ -143,6 +151,7 @@ func NewManager(opts Opts) InvocationManager {
 	return &requestCtxManager{
 		fn:         opts.Fn,
 		cancel:     opts.Cancel,
+		onOp:       opts.OnOp,
 		request:    opts.Request,
 		indexes:    map[string]int{},
 		l:          &sync.RWMutex{},
@@ -183,6 +192,8 @@ type requestCtxManager struct {
 	signingKey string
 	// cancel ends the context and prevents any other tools from running.
 	cancel func()
+	// onOp is called with each appended op.
+	onOp func(op GeneratorOpcode)
 	// err stores the error from any step ran.
 	err error
 	// Ops holds a list of buffered generator opcodes to send to the executor
@@ -211,6 +222,10 @@ type requestCtxManager struct {
 
 	// t returns the time since the epoch since the request started.
 	t time.Time
+
+	// draining is set when the invocation should return control at the next
+	// step boundary.
+	draining atomic.Bool
 }
 
 func (r *requestCtxManager) SigningKey() string {
@@ -237,13 +252,6 @@ func (r *requestCtxManager) Err() error {
 }
 
 func (r *requestCtxManager) AppendOp(ctx context.Context, op GeneratorOpcode) {
-	r.l.Lock()
-	defer r.l.Unlock()
-
-	if r.cancel == nil {
-		r.cancel = func() {} // normalization.
-	}
-
 	// Always add the current parallelism mode to the opcode we're appending.
 	op.SetParallelMode(ParallelMode(ctx))
 
@@ -255,6 +263,19 @@ func (r *requestCtxManager) AppendOp(ctx context.Context, op GeneratorOpcode) {
 	}
 	r.opsLock.Unlock()
 
+	// Report the op before locking, as reporting may write to a slow client and
+	// mustn't block other calls to the manager.
+	if r.onOp != nil {
+		r.onOp(op)
+	}
+
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	if r.cancel == nil {
+		r.cancel = func() {} // normalization.
+	}
+
 	// If we're planning multiple steps, append and continue on without any hijacking
 	// in every case.  Without this, we won't continue to plan the next set of parallel
 	// steps.
@@ -296,26 +317,39 @@ func (r *requestCtxManager) AppendOp(ctx context.Context, op GeneratorOpcode) {
 			panic(ControlHijack{})
 		}
 
-		r.checkpointer.WithStep(ctx, op, func(done []opcode.Step, err error) {
-			if err == nil {
-				// drop checkpointed steps from the response buffer.  the callback shares
-				// this slice with appends and response reads.
-				r.opsLock.Lock()
-				for _, op := range done {
-					r.ops = slices.DeleteFunc(r.ops, func(f opcode.Step) bool {
-						return op.ID == f.ID
-					})
-				}
-				r.opsLock.Unlock()
-				return
-			}
-			logger.Default().Warn("error checkpointing state, falling back to async response", "error", err)
-		})
+		if r.draining.Load() {
+			r.cancel()
+			panic(ControlHijack{})
+		}
+
+		if yieldBy, ok := YieldBy(ctx); ok && !time.Now().Before(yieldBy) {
+			r.cancel()
+			panic(ControlHijack{})
+		}
+
+		r.checkpointer.WithStep(ctx, op, r.checkpointed)
 	default:
 		// Do nothing else.
 	}
 }
 
+// checkpointed is called once steps have been checkpointed.
+func (r *requestCtxManager) checkpointed(done []opcode.Step, err error) {
+	if err == nil {
+		// drop checkpointed steps from the response buffer.  the callback shares
+		// this slice with appends and response reads.
+		r.opsLock.Lock()
+		for _, op := range done {
+			r.ops = slices.DeleteFunc(r.ops, func(f opcode.Step) bool {
+				return op.ID == f.ID
+			})
+		}
+		r.opsLock.Unlock()
+		return
+	}
+	logger.Default().Warn("error checkpointing state, falling back to async response", "error", err)
+}
+
 func (r *requestCtxManager) Ops() []GeneratorOpcode {
 	// copy the response buffer because checkpoint callbacks share the slice.
 	r.opsLock.Lock()
@@ -396,6 +430,11 @@ func (r *requestCtxManager) CloseCheckpointer() {
 	r.checkpointer.Close()
 }
 
+func (r *requestCtxManager) Drain(ctx context.Context) {
+	r.draining.Store(true)
+	r.checkpointer.Flush(ctx, r.checkpointed)
+}
+
 func (r *requestCtxManager) NewOp(op enums.Opcode, id string) UnhashedOp {
 	r.l.Lock()
 	defer r.l.Unlock()
@@ -5,6 +5,7 @@ import (
 	"runtime"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/inngest/inngest/pkg/enums"
 	internalcheckpoint "github.com/inngest/inngestgo/internal/checkpoint"
@@ -94,8 +95,60 @@ func (c *concurrentCallbackCheckpointer) WithStep(ctx context.Context, step opco
 	}()
 }
 
+func (c *concurrentCallbackCheckpointer) Flush(ctx context.Context, cb internalcheckpoint.Callback) {}
+
 func (c *concurrentCallbackCheckpointer) Close() {}
 
+// recordingCheckpointer records the steps that are checkpointed, without
+// calling back.
+type recordingCheckpointer struct {
+	l     sync.Mutex
+	steps []opcode.Step
+}
+
+func (c *recordingCheckpointer) WithStep(ctx context.Context, step opcode.Step, cb internalcheckpoint.Callback) {
+	c.l.Lock()
+	defer c.l.Unlock()
+	c.steps = append(c.steps, step)
+}
+
+func (c *recordingCheckpointer) Flush(ctx context.Context, cb internalcheckpoint.Callback) {}
+
+func (c *recordingCheckpointer) Close() {}
+
+func TestDrainYieldsAtNextStep(t *testing.T) {
+	cancelled := false
+	mgr := NewManager(Opts{
+		Mode: StepModeCheckpoint,
+		Fn: testServableFunction{
+			config: fn.FunctionOpts{
+				Checkpoint: &internalcheckpoint.Config{BatchSteps: 10},
+			},
+		},
+		Request: &Request{},
+		Cancel:  func() { cancelled = true },
+	})
+	mgr.(*requestCtxManager).checkpointer = &concurrentCallbackCheckpointer{}
+
+	mgr.Drain(context.Background())
+
+	defer func() {
+		if _, ok := recover().(ControlHijack); !ok {
+			t.Fatal("expected the step to return control")
+		}
+		if !cancelled {
+			t.Fatal("expected the function context to be cancelled")
+		}
+		if len(mgr.Ops()) != 1 {
+			t.Fatalf("expected the step to be returned, got %d ops", len(mgr.Ops()))
+		}
+	}()
+	mgr.AppendOp(context.Background(), GeneratorOpcode{
+		Op: enums.OpcodeStepRun,
+		ID: "step-1",
+	})
+}
+
 type testServableFunction struct {
 	config fn.FunctionOpts
 }
@@ -127,3 +180,60 @@ func (f testServableFunction) ZeroEvent() any {
 func (f testServableFunction) Func() any {
 	return nil
 }
+
+func TestYieldByReturnsControl(t *testing.T) {
+	mgr := NewManager(Opts{
+		Mode: StepModeCheckpoint,
+		Fn: testServableFunction{
+			config: fn.FunctionOpts{
+				Checkpoint: &internalcheckpoint.Config{BatchSteps: 10},
+			},
+		},
+		Request: &Request{},
+	})
+	mgr.(*requestCtxManager).checkpointer = &recordingCheckpointer{}
+
+	// Steps continue to run before the deadline.
+	ctx := WithYieldBy(context.Background(), time.Now().Add(time.Hour))
+	mgr.AppendOp(ctx, GeneratorOpcode{Op: enums.OpcodeStepRun, ID: "step-1"})
+
+	defer func() {
+		if _, ok := recover().(ControlHijack); !ok {
+			t.Fatal("expected the step to return control")
+		}
+	}()
+	ctx = WithYieldBy(context.Background(), time.Now())
+	mgr.AppendOp(ctx, GeneratorOpcode{Op: enums.OpcodeStepRun, ID: "step-2"})
+}
+
+func TestOnOpDoesntBlockManager(t *testing.T) {
+	reporting := make(chan struct{})
+	unblock := make(chan struct{})
+	mgr := NewManager(Opts{
+		Mode:    StepModeYield,
+		Fn:      testServableFunction{},
+		Request: &Request{},
+		OnOp: func(op GeneratorOpcode) {
+			close(reporting)
+			<-unblock
+		},
+	})
+	defer close(unblock)
+
+	// Parallel steps are appended without returning control.
+	ctx := context.WithValue(context.Background(), ParallelKey, true)
+	go mgr.AppendOp(ctx, GeneratorOpcode{Op: enums.OpcodeStepRun, ID: "step-1"})
+	<-reporting
+
+	// Other steps continue while the op is being reported.
+	done := make(chan struct{})
+	go func() {
+		mgr.NewOp(enums.OpcodeStepRun, "step-2")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the manager not to be locked while reporting ops")
+	}
+}
@@ -0,0 +1,23 @@
+package sdkrequest
+
+import (
+	"context"
+	"time"
+)
+
+type yieldByCtxKeyType struct{}
+
+var yieldByCtxKey = yieldByCtxKeyType{}
+
+// WithYieldBy returns a context in which checkpointed functions return control
+// to the executor instead of running further steps once the given time passes,
+// eg. ahead of a serverless platform's request timeout.
+func WithYieldBy(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, yieldByCtxKey, t)
+}
+
+// YieldBy returns the time set by WithYieldBy, if any.
+func YieldBy(ctx context.Context) (time.Time, bool) {
+	t, ok := ctx.Value(yieldByCtxKey).(time.Time)
+	return t, ok
+}
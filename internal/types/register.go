@@ -10,9 +10,10 @@ const (
 )
 
 const (
-	InBandSyncV1 string = "v1"
-	TrustProbeV1 string = "v1"
-	ConnectV1    string = "v1"
+	InBandSyncV1       string = "v1"
+	TrustProbeV1       string = "v1"
+	ConnectV1          string = "v1"
+	ServerSentEventsV1 string = "v1"
 )
 
 // RegisterRequest represents a new deploy request from SDK-based functions.
@@ -64,4 +65,7 @@ type Capabilities struct {
 	InBandSync string `json:"in_band_sync"`
 	TrustProbe string `json:"trust_probe"`
 	Connect    string `json:"connect"`
+	// ServerSentEvents is set when the SDK can stream invocations as
+	// server-sent events to requests which accept them.
+	ServerSentEvents string `json:"server_sent_events,omitempty"`
 }
@@ -0,0 +1,185 @@
+package inngestgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/inngest/inngestgo/api"
+)
+
+const (
+	defaultInvokeTimeout      = 30 * time.Second
+	defaultInvokePollInterval = 250 * time.Millisecond
+)
+
+// InvokeAndWaitOpts configures InvokeAndWait.
+type InvokeAndWaitOpts struct {
+	// Event is the event to send, which must trigger the function.
+	Event any
+	// FunctionID is the function whose run is awaited, which the event must
+	// trigger.  This may be the function's ID, as set in FunctionOpts, or its fully
+	// qualified ID including the app ID.
+	FunctionID string
+	// Timeout is the maximum time to wait for the run to finish, defaulting to
+	// 30 seconds.  The context's deadline also applies.
+	Timeout time.Duration
+	// PollInterval is how often to check the run's status, defaulting to 250ms.
+	PollInterval time.Duration
+}
+
+// RunTimeoutError is returned by InvokeAndWait when the run doesn't finish before
+// the timeout.  The run continues, and can be checked later using its ID.
+type RunTimeoutError struct {
+	// EventID is the ID of the event which triggered the run.
+	EventID string
+	// RunID is the ID of the run, or empty if the run hadn't started.
+	RunID string
+	// Err is the context's error.
+	Err error
+}
+
+func (e *RunTimeoutError) Error() string {
+	if e.RunID == "" {
+		return fmt.Sprintf("timed out waiting for run for event %s to start", e.EventID)
+	}
+	return fmt.Sprintf("timed out waiting for run %s to finish", e.RunID)
+}
+
+func (e *RunTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// RunError is returned by InvokeAndWait when the run fails or is cancelled.
+type RunError struct {
+	// RunID is the ID of the run.
+	RunID string
+	// Status is the run's final status.
+	Status api.RunStatus
+	// Output is the run's output, typically containing the error.
+	Output json.RawMessage
+}
+
+func (e *RunError) Error() string {
+	return fmt.Sprintf("run %s %s: %s", e.RunID, e.Status, e.Output)
+}
+
+// InvokeAndWait sends an event and waits for the run it triggers to finish,
+// returning the run's output.  This allows functions to be used for request and
+// response workloads from outside of Inngest:
+//
+//	out, err := inngestgo.InvokeAndWait[Result](ctx, client, inngestgo.InvokeAndWaitOpts{
+//		Event:   inngestgo.Event{Name: "report/generate", Data: data},
+//		Timeout: 10 * time.Second,
+//	})
+//
+// If the run doesn't finish in time, this returns a *RunTimeoutError containing
+// the run's ID, which is empty if the event didn't start a run of the function in
+// time.  If the run fails, this returns a *RunError.
+//
+// Runs are always started by sending an event and awaited by polling the REST
+// API.  Invoking a function directly by its ID without an event, and awaiting
+// results via the realtime package, aren't supported.
+func InvokeAndWait[T any](ctx context.Context, c Client, opts InvokeAndWaitOpts) (T, error) {
+	var out T
+
+	cImpl, ok := c.(*apiClient)
+	if !ok {
+		return out, fmt.Errorf("invalid client type")
+	}
+	if opts.Event == nil {
+		return out, fmt.Errorf("an event is required")
+	}
+	if opts.FunctionID == "" {
+		return out, fmt.Errorf("a function ID is required")
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultInvokeTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultInvokePollInterval
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	// Runs reference their function by its internal ID, so resolve this before
+	// sending the event.
+	client := cImpl.restAPI(ctx)
+	slug := opts.FunctionID
+	if !strings.HasPrefix(slug, cImpl.AppID()+"-") {
+		slug = cImpl.AppID() + "-" + slug
+	}
+	function, err := client.GetFunction(ctx, slug)
+	if err != nil {
+		return out, fmt.Errorf("error fetching function %s: %w", slug, err)
+	}
+
+	eventID, err := c.Send(ctx, opts.Event)
+	if err != nil {
+		return out, err
+	}
+
+	timeout := &RunTimeoutError{EventID: eventID}
+
+	// Resolve the run triggered by the event.
+	for timeout.RunID == "" {
+		page, err := client.ListEventRuns(ctx, eventID, api.PageOpts{})
+		if err != nil && ctx.Err() == nil {
+			return out, fmt.Errorf("error fetching runs for event %s: %w", eventID, err)
+		}
+		if page != nil {
+			if run := selectRun(page.Data, function); run != nil {
+				timeout.RunID = run.RunID
+				break
+			}
+		}
+		if err := sleep(ctx, opts.PollInterval); err != nil {
+			timeout.Err = err
+			return out, timeout
+		}
+	}
+
+	for {
+		run, err := client.GetRun(ctx, timeout.RunID)
+		if err != nil && ctx.Err() == nil {
+			return out, fmt.Errorf("error fetching run %s: %w", timeout.RunID, err)
+		}
+		if run != nil && run.Status.Done() {
+			if run.Status != api.RunStatusCompleted {
+				return out, &RunError{RunID: run.RunID, Status: run.Status, Output: run.Output}
+			}
+			return api.RunOutput[T](run)
+		}
+		if err := sleep(ctx, opts.PollInterval); err != nil {
+			timeout.Err = err
+			return out, timeout
+		}
+	}
+}
+
+// selectRun returns the run of the given function, or nil if the function's run
+// hasn't started yet.  Other functions triggered by the event may start first,
+// so their runs are skipped rather than treated as an error.
+func selectRun(runs []api.Run, function *api.Function) *api.Run {
+	for i, run := range runs {
+		if run.FunctionID == function.ID {
+			return &runs[i]
+		}
+	}
+	return nil
+}
+
+// restAPI returns a client for the REST API using the client's signing keys.
+func (a apiClient) restAPI(ctx context.Context) *api.Client {
+	keys := a.h.signingKeys(ctx)
+	return api.New(api.Opts{
+		SigningKey:         keys.Current,
+		SigningKeyFallback: keys.Fallback,
+		Env:                a.h.GetEnv(),
+		BaseURL:            a.h.GetAPIBaseURL(),
+		HTTPClient:         a.HTTPClient,
+	})
+}
@@ -0,0 +1,186 @@
+package inngestgo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/inngest/inngestgo/api"
+	"github.com/stretchr/testify/require"
+)
+
+// reportFunction is the function returned for "app-report" by newInvokeClient.
+const reportFunction = `{"data":{"id":"2d3c6a4e-6c8b-4b53-9a0e-5f5f1f2b7a10","slug":"app-report"}}`
+
+func newInvokeClient(t *testing.T, handler http.HandlerFunc) Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/v1/functions/app-report" {
+			_, _ = w.Write([]byte(reportFunction))
+			return
+		}
+		handler(w, req)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(ClientOpts{
+		AppID:           "app",
+		EventKey:        StrPtr("key"),
+		EventAPIBaseURL: StrPtr(srv.URL),
+		APIBaseURL:      StrPtr(srv.URL),
+	})
+	require.NoError(t, err)
+	return c
+}
+
+func TestInvokeAndWait(t *testing.T) {
+	type Result struct {
+		Total int `json:"total"`
+	}
+
+	opts := InvokeAndWaitOpts{
+		Event:        Event{Name: "report/generate"},
+		FunctionID:   "report",
+		PollInterval: time.Millisecond,
+	}
+
+	t.Run("completed", func(t *testing.T) {
+		r := require.New(t)
+		var polls int32
+		c := newInvokeClient(t, func(w http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/e/key":
+				_, _ = w.Write([]byte(`{"ids":["evt-1"],"status":200}`))
+			case "/v1/events/evt-1/runs":
+				// The run starts after the first poll.
+				if atomic.AddInt32(&polls, 1) == 1 {
+					_, _ = w.Write([]byte(`{"data":[]}`))
+					return
+				}
+				_, _ = w.Write([]byte(`{"data":[
+					{"run_id":"run-0","function_id":"8e1b5f0a-1f7c-4d0e-8f64-3c1a2b9d0e11","status":"Running"},
+					{"run_id":"run-1","function_id":"2d3c6a4e-6c8b-4b53-9a0e-5f5f1f2b7a10","status":"Running"}
+				]}`))
+			case "/v1/runs/run-1":
+				_, _ = w.Write([]byte(`{"data":{"run_id":"run-1","status":"Completed","output":{"total":3}}}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+
+		out, err := InvokeAndWait[Result](context.Background(), c, opts)
+		r.NoError(err)
+		r.Equal(3, out.Total)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		r := require.New(t)
+		c := newInvokeClient(t, func(w http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/e/key":
+				_, _ = w.Write([]byte(`{"ids":["evt-1"],"status":200}`))
+			case "/v1/events/evt-1/runs":
+				_, _ = w.Write([]byte(`{"data":[{"run_id":"run-1","function_id":"2d3c6a4e-6c8b-4b53-9a0e-5f5f1f2b7a10"}]}`))
+			case "/v1/runs/run-1":
+				_, _ = w.Write([]byte(`{"data":{"run_id":"run-1","status":"Failed","output":{"message":"boom"}}}`))
+			}
+		})
+
+		_, err := InvokeAndWait[Result](context.Background(), c, opts)
+		var runErr *RunError
+		r.ErrorAs(err, &runErr)
+		r.Equal("run-1", runErr.RunID)
+		r.Equal(api.RunStatusFailed, runErr.Status)
+		r.JSONEq(`{"message":"boom"}`, string(runErr.Output))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		r := require.New(t)
+		c := newInvokeClient(t, func(w http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/e/key":
+				_, _ = w.Write([]byte(`{"ids":["evt-1"],"status":200}`))
+			case "/v1/events/evt-1/runs":
+				_, _ = w.Write([]byte(`{"data":[{"run_id":"run-1","function_id":"2d3c6a4e-6c8b-4b53-9a0e-5f5f1f2b7a10"}]}`))
+			case "/v1/runs/run-1":
+				_, _ = w.Write([]byte(`{"data":{"run_id":"run-1","status":"Running"}}`))
+			}
+		})
+
+		opts := opts
+		opts.Timeout = 50 * time.Millisecond
+		_, err := InvokeAndWait[Result](context.Background(), c, opts)
+
+		var timeoutErr *RunTimeoutError
+		r.ErrorAs(err, &timeoutErr)
+		r.Equal("evt-1", timeoutErr.EventID)
+		r.Equal("run-1", timeoutErr.RunID)
+		r.ErrorIs(err, context.DeadlineExceeded)
+	})
+
+	t.Run("fully qualified ID", func(t *testing.T) {
+		r := require.New(t)
+		c := newInvokeClient(t, func(w http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/e/key":
+				_, _ = w.Write([]byte(`{"ids":["evt-1"],"status":200}`))
+			case "/v1/events/evt-1/runs":
+				_, _ = w.Write([]byte(`{"data":[{"run_id":"run-1","function_id":"2d3c6a4e-6c8b-4b53-9a0e-5f5f1f2b7a10"}]}`))
+			case "/v1/runs/run-1":
+				_, _ = w.Write([]byte(`{"data":{"run_id":"run-1","status":"Completed","output":{"total":1}}}`))
+			}
+		})
+
+		opts := opts
+		opts.FunctionID = "app-report"
+		out, err := InvokeAndWait[Result](context.Background(), c, opts)
+		r.NoError(err)
+		r.Equal(1, out.Total)
+	})
+
+	t.Run("function not triggered", func(t *testing.T) {
+		// Runs of other functions are skipped while waiting for the function's
+		// run to start.
+		r := require.New(t)
+		c := newInvokeClient(t, func(w http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/e/key":
+				_, _ = w.Write([]byte(`{"ids":["evt-1"],"status":200}`))
+			case "/v1/events/evt-1/runs":
+				_, _ = w.Write([]byte(`{"data":[{"run_id":"run-0","function_id":"8e1b5f0a-1f7c-4d0e-8f64-3c1a2b9d0e11"}]}`))
+			}
+		})
+
+		opts := opts
+		opts.Timeout = 50 * time.Millisecond
+		_, err := InvokeAndWait[Result](context.Background(), c, opts)
+
+		var timeoutErr *RunTimeoutError
+		r.ErrorAs(err, &timeoutErr)
+		r.Equal("evt-1", timeoutErr.EventID)
+		r.Empty(timeoutErr.RunID)
+	})
+
+	t.Run("unknown function", func(t *testing.T) {
+		r := require.New(t)
+		var sent bool
+		c := newInvokeClient(t, func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Path == "/e/key" {
+				sent = true
+			}
+			w.WriteHeader(http.StatusNotFound)
+		})
+
+		opts := opts
+		opts.FunctionID = "missing"
+		_, err := InvokeAndWait[Result](context.Background(), c, opts)
+		r.ErrorIs(err, api.ErrNotFound)
+		r.False(sent)
+
+		opts.FunctionID = ""
+		_, err = InvokeAndWait[Result](context.Background(), c, opts)
+		r.ErrorContains(err, "function ID is required")
+	})
+}
@@ -0,0 +1,194 @@
+// Package lambdahttp serves http.Handlers, such as Client.Serve, from AWS Lambda
+// functions behind API Gateway HTTP APIs or function URLs.
+//
+// Handlers accept and return the version 2.0 payload format as JSON, so they can
+// be passed directly to the Lambda Go runtime without this package depending on
+// it:
+//
+//	lambda.Start(lambdahttp.NewHandler(client.Serve(), lambdahttp.Opts{}))
+package lambdahttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/inngest/inngestgo/internal/sdkrequest"
+)
+
+// DefaultYieldMargin is the default time before a Lambda invocation's deadline
+// after which functions stop running steps.
+const DefaultYieldMargin = 10 * time.Second
+
+// Request is an API Gateway HTTP API or function URL request, in the version 2.0
+// payload format.
+type Request struct {
+	Version         string            `json:"version"`
+	RouteKey        string            `json:"routeKey"`
+	RawPath         string            `json:"rawPath"`
+	RawQueryString  string            `json:"rawQueryString"`
+	Cookies         []string          `json:"cookies,omitempty"`
+	Headers         map[string]string `json:"headers"`
+	Body            string            `json:"body,omitempty"`
+	IsBase64Encoded bool              `json:"isBase64Encoded"`
+	RequestContext  RequestContext    `json:"requestContext"`
+}
+
+// RequestContext describes the request as received by AWS.
+type RequestContext struct {
+	DomainName string             `json:"domainName"`
+	RequestID  string             `json:"requestId"`
+	HTTP       RequestContextHTTP `json:"http"`
+}
+
+// RequestContextHTTP describes the HTTP request as received by AWS.
+type RequestContextHTTP struct {
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Protocol  string `json:"protocol"`
+	SourceIP  string `json:"sourceIp"`
+	UserAgent string `json:"userAgent"`
+}
+
+// Response is an API Gateway HTTP API or function URL response, in the version
+// 2.0 payload format.
+type Response struct {
+	StatusCode      int               `json:"statusCode"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	Cookies         []string          `json:"cookies,omitempty"`
+	Body            string            `json:"body"`
+	IsBase64Encoded bool              `json:"isBase64Encoded"`
+}
+
+// Opts configures Lambda handlers.
+type Opts struct {
+	// YieldMargin is the time before the Lambda invocation's deadline after
+	// which checkpointed functions stop running steps and return control to
+	// Inngest, which continues the function in a new request.  This should
+	// exceed the duration of the function's longest step, so that a step never
+	// runs past the Lambda timeout.  If zero, DefaultYieldMargin is used.
+	YieldMargin time.Duration
+}
+
+// NewHandler returns a Lambda handler which serves requests using the given
+// http.Handler, buffering each response.
+func NewHandler(h http.Handler, opts Opts) func(context.Context, Request) (Response, error) {
+	return func(ctx context.Context, req Request) (Response, error) {
+		r, err := newHTTPRequest(ctx, req, opts)
+		if err != nil {
+			return Response{}, err
+		}
+
+		w := &bufferedWriter{header: http.Header{}}
+		h.ServeHTTP(w, r)
+		return w.response(), nil
+	}
+}
+
+// newHTTPRequest converts a Lambda request into an http.Request.  If the context
+// has a deadline, functions are told to yield ahead of it.
+func newHTTPRequest(ctx context.Context, req Request, opts Opts) (*http.Request, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		margin := opts.YieldMargin
+		if margin == 0 {
+			margin = DefaultYieldMargin
+		}
+		ctx = sdkrequest.WithYieldBy(ctx, deadline.Add(-margin))
+	}
+
+	body := []byte(req.Body)
+	if req.IsBase64Encoded {
+		var err error
+		body, err = base64.StdEncoding.DecodeString(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding request body: %w", err)
+		}
+	}
+
+	u := &url.URL{
+		Scheme:   "https",
+		Host:     req.RequestContext.DomainName,
+		Path:     req.RawPath,
+		RawQuery: req.RawQueryString,
+	}
+	r, err := http.NewRequestWithContext(ctx, req.RequestContext.HTTP.Method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	for k, v := range req.Headers {
+		r.Header.Set(k, v)
+	}
+	if len(req.Cookies) > 0 {
+		r.Header.Set("Cookie", strings.Join(req.Cookies, "; "))
+	}
+	if r.Header.Get("X-Forwarded-Proto") == "" {
+		// API Gateway and function URLs are only served over HTTPS.
+		r.Header.Set("X-Forwarded-Proto", "https")
+	}
+	if host := r.Header.Get("Host"); host != "" {
+		r.Host = host
+	}
+	r.RemoteAddr = req.RequestContext.HTTP.SourceIP
+	r.RequestURI = u.RequestURI()
+	return r, nil
+}
+
+// splitHeaders converts response headers to the Lambda format, which has
+// cookies separately and a single value per header.
+func splitHeaders(header http.Header) (map[string]string, []string) {
+	headers := map[string]string{}
+	var cookies []string
+	for k, v := range header {
+		if k == "Set-Cookie" {
+			cookies = append(cookies, v...)
+			continue
+		}
+		headers[k] = strings.Join(v, ",")
+	}
+	return headers, cookies
+}
+
+// bufferedWriter is an http.ResponseWriter which buffers the response.
+type bufferedWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
+
+func (w *bufferedWriter) response() Response {
+	res := Response{StatusCode: w.status}
+	if res.StatusCode == 0 {
+		res.StatusCode = http.StatusOK
+	}
+	res.Headers, res.Cookies = splitHeaders(w.header)
+
+	if utf8.Valid(w.body.Bytes()) {
+		res.Body = w.body.String()
+	} else {
+		res.Body = base64.StdEncoding.EncodeToString(w.body.Bytes())
+		res.IsBase64Encoded = true
+	}
+	return res
+}
@@ -0,0 +1,157 @@
+package lambdahttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/inngest/inngestgo"
+	"github.com/inngest/inngestgo/internal/sdkrequest"
+	"github.com/stretchr/testify/require"
+)
+
+func loadFixture(t *testing.T, name string) Request {
+	byt, err := os.ReadFile("testdata/" + name)
+	require.NoError(t, err)
+
+	var req Request
+	require.NoError(t, json.Unmarshal(byt, &req))
+	return req
+}
+
+// echo responds with details of the request.
+var echo = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	w.Header().Add("Set-Cookie", "a=1")
+	w.Header().Add("Set-Cookie", "b=2")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"method": r.Method,
+		"url":    r.URL.String(),
+		"host":   r.Host,
+		"remote": r.RemoteAddr,
+		"cookie": r.Header.Get("Cookie"),
+		"sig":    r.Header.Get("X-Inngest-Signature"),
+		"body":   string(body),
+	})
+})
+
+func TestNewHandler(t *testing.T) {
+	r := require.New(t)
+
+	res, err := NewHandler(echo, Opts{})(context.Background(), loadFixture(t, "api_gateway_v2.json"))
+	r.NoError(err)
+	r.Equal(http.StatusAccepted, res.StatusCode)
+	r.Equal("application/json", res.Headers["Content-Type"])
+	r.Equal([]string{"a=1", "b=2"}, res.Cookies)
+	r.False(res.IsBase64Encoded)
+
+	var out map[string]string
+	r.NoError(json.Unmarshal([]byte(res.Body), &out))
+	r.Equal(map[string]string{
+		"method": http.MethodPost,
+		"url":    "https://abc123.execute-api.us-east-1.amazonaws.com/api/inngest?fnId=app-fn&stepId=step",
+		"host":   "abc123.execute-api.us-east-1.amazonaws.com",
+		"remote": "203.0.113.10",
+		"cookie": "session=abc; theme=dark",
+		"sig":    "t=1700000000&s=sig",
+		"body":   `{"event":{"name":"test/event"}}`,
+	}, out)
+}
+
+func TestNewHandlerBinaryBody(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte{0xff, 0xfe})
+	})
+
+	res, err := NewHandler(h, Opts{})(context.Background(), loadFixture(t, "function_url.json"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.True(t, res.IsBase64Encoded)
+	require.Equal(t, "//4=", res.Body)
+}
+
+func TestNewHandlerServe(t *testing.T) {
+	c, err := inngestgo.NewClient(inngestgo.ClientOpts{
+		AppID: "lambda",
+		Dev:   inngestgo.BoolPtr(true),
+	})
+	require.NoError(t, err)
+
+	res, err := NewHandler(c.Serve(), Opts{})(context.Background(), loadFixture(t, "function_url.json"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var inspection map[string]any
+	require.NoError(t, json.Unmarshal([]byte(res.Body), &inspection))
+	require.Equal(t, "dev", inspection["mode"])
+}
+
+func TestYieldBy(t *testing.T) {
+	var (
+		yieldBy time.Time
+		ok      bool
+	)
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		yieldBy, ok = sdkrequest.YieldBy(r.Context())
+	})
+	req := loadFixture(t, "function_url.json")
+
+	t.Run("no deadline", func(t *testing.T) {
+		_, err := NewHandler(h, Opts{})(context.Background(), req)
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		deadline := time.Now().Add(time.Minute)
+		ctx, cancel := context.WithDeadline(context.Background(), deadline)
+		defer cancel()
+
+		_, err := NewHandler(h, Opts{YieldMargin: 15 * time.Second})(ctx, req)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, deadline.Add(-15*time.Second), yieldBy)
+	})
+}
+
+func TestNewStreamingHandler(t *testing.T) {
+	r := require.New(t)
+
+	res, err := NewStreamingHandler(echo, Opts{})(context.Background(), loadFixture(t, "api_gateway_v2.json"))
+	r.NoError(err)
+	r.Equal(streamingContentType, res.ContentType())
+
+	byt, err := io.ReadAll(res)
+	r.NoError(err)
+	r.NoError(res.Close())
+
+	prelude, body, found := bytes.Cut(byt, streamingPreludeDelimiter)
+	r.True(found)
+	r.JSONEq(`{
+		"statusCode": 202,
+		"headers": {"Content-Type": "application/json"},
+		"cookies": ["a=1", "b=2"]
+	}`, string(prelude))
+
+	var out map[string]string
+	r.NoError(json.Unmarshal(body, &out))
+	r.Equal(`{"event":{"name":"test/event"}}`, out["body"])
+}
+
+func TestNewStreamingHandlerEmptyResponse(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	res, err := NewStreamingHandler(h, Opts{})(context.Background(), loadFixture(t, "function_url.json"))
+	require.NoError(t, err)
+
+	byt, err := io.ReadAll(res)
+	require.NoError(t, err)
+	require.Equal(t, append([]byte(`{"statusCode":200}`), streamingPreludeDelimiter...), byt)
+}
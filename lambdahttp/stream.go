@@ -0,0 +1,123 @@
+package lambdahttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// streamingContentType is the content type of streamed function URL responses.
+const streamingContentType = "application/vnd.awslambda.http-integration-response"
+
+// streamingPreludeDelimiter separates a streamed response's JSON prelude from its
+// body.
+var streamingPreludeDelimiter = make([]byte, 8)
+
+// StreamingResponse is a function URL response which is streamed as it's
+// written, for functions using the RESPONSE_STREAM invoke mode.  This implements
+// io.ReadCloser and ContentType as expected by the Lambda Go runtime.
+type StreamingResponse struct {
+	r *io.PipeReader
+}
+
+// Read reads the response, which is a JSON prelude containing the status code,
+// headers and cookies followed by the body.
+func (s *StreamingResponse) Read(p []byte) (int, error) {
+	return s.r.Read(p)
+}
+
+// Close stops reading the response.  Further writes by the handler fail.
+func (s *StreamingResponse) Close() error {
+	return s.r.Close()
+}
+
+// ContentType returns the content type of the streamed response.
+func (s *StreamingResponse) ContentType() string {
+	return streamingContentType
+}
+
+// NewStreamingHandler returns a Lambda handler which serves requests using the
+// given http.Handler, streaming each response as it's written.  This allows
+// invocations using server-sent events or long-running streamed responses to
+// keep the connection alive and report progress.
+func NewStreamingHandler(h http.Handler, opts Opts) func(context.Context, Request) (*StreamingResponse, error) {
+	return func(ctx context.Context, req Request) (*StreamingResponse, error) {
+		r, err := newHTTPRequest(ctx, req, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		pr, pw := io.Pipe()
+		w := &streamingWriter{header: http.Header{}, w: pw}
+		go func() {
+			defer func() {
+				if rec := recover(); rec != nil {
+					_ = pw.CloseWithError(fmt.Errorf("handler panicked: %v", rec))
+				}
+			}()
+			h.ServeHTTP(w, r)
+			if err := w.writePrelude(http.StatusOK); err != nil {
+				_ = pw.CloseWithError(err)
+				return
+			}
+			_ = pw.Close()
+		}()
+		return &StreamingResponse{r: pr}, nil
+	}
+}
+
+// streamingWriter is an http.ResponseWriter which writes the response to a
+// pipe, starting with the prelude once the status is known.
+type streamingWriter struct {
+	header http.Header
+	w      *io.PipeWriter
+
+	l       sync.Mutex
+	started bool
+}
+
+func (w *streamingWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *streamingWriter) WriteHeader(status int) {
+	_ = w.writePrelude(status)
+}
+
+func (w *streamingWriter) Write(b []byte) (int, error) {
+	if err := w.writePrelude(http.StatusOK); err != nil {
+		return 0, err
+	}
+	return w.w.Write(b)
+}
+
+// Flush is a no-op, as writes are passed to the reader as they happen.
+func (w *streamingWriter) Flush() {}
+
+func (w *streamingWriter) writePrelude(status int) error {
+	w.l.Lock()
+	defer w.l.Unlock()
+	if w.started {
+		return nil
+	}
+	w.started = true
+
+	prelude := struct {
+		StatusCode int               `json:"statusCode"`
+		Headers    map[string]string `json:"headers,omitempty"`
+		Cookies    []string          `json:"cookies,omitempty"`
+	}{StatusCode: status}
+	prelude.Headers, prelude.Cookies = splitHeaders(w.header)
+
+	byt, err := json.Marshal(prelude)
+	if err != nil {
+		return fmt.Errorf("error encoding response prelude: %w", err)
+	}
+	if _, err := w.w.Write(append(byt, streamingPreludeDelimiter...)); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+package inngestgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/inngest/inngest/pkg/syscode"
+)
+
+// multiAppQueryParam is the query parameter identifying the app which a request
+// is for when serving multiple apps.
+const multiAppQueryParam = "appId"
+
+// MultiAppHandler returns an HTTP handler which serves several apps from a single
+// path, allowing one service to host several apps behind one route.
+//
+// Each app is synced using the handler's URL with an appId query parameter, eg.
+// "https://example.com/api/inngest?appId=billing", so that requests from Inngest
+// are routed to the app.  Requests are routed as follows:
+//
+//   - Requests with an appId query parameter are served by that app.
+//   - Sync requests without an appId sync every app.
+//   - Function invocations without an appId are served by the app with the
+//     function's fully qualified ID, or else the only app with a function of that
+//     ID.  If several apps have a function with the ID, the request is rejected.
+//   - All other requests are served by the first app.
+//
+// The given ServeOpts are applied to every app.  With SyncOnStart, each app is
+// synced on the handler's first request using the configured URL with its appId
+// query parameter.
+func MultiAppHandler(apps []Client, opts ServeOpts) (http.Handler, error) {
+	if len(apps) == 0 {
+		return nil, fmt.Errorf("at least one app is required")
+	}
+
+	syncOnStart := opts.SyncOnStart
+	opts.SyncOnStart = false
+
+	m := &multiAppHandler{byID: map[string]*handler{}}
+	for _, c := range apps {
+		cImpl, ok := c.(*apiClient)
+		if !ok {
+			return nil, fmt.Errorf("invalid client type")
+		}
+		if _, ok := m.byID[c.AppID()]; ok {
+			return nil, fmt.Errorf("duplicate app ID: %s", c.AppID())
+		}
+
+		_ = cImpl.ServeWithOpts(opts)
+		m.apps = append(m.apps, cImpl.h)
+		m.byID[c.AppID()] = cImpl.h
+	}
+
+	if syncOnStart {
+		for _, h := range m.apps {
+			h.syncOnStart(true)
+		}
+	}
+	return m, nil
+}
+
+type multiAppHandler struct {
+	apps []*handler
+	byID map[string]*handler
+}
+
+func (m *multiAppHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for _, h := range m.apps {
+		h.startSync()
+	}
+
+	if appID := r.URL.Query().Get(multiAppQueryParam); appID != "" {
+		h, ok := m.byID[appID]
+		if !ok {
+			writeMultiAppError(w, http.StatusNotFound, fmt.Sprintf("app not found: %s", appID))
+			return
+		}
+		h.ServeHTTP(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		m.syncAll(w, r)
+		return
+	case http.MethodPost:
+		if fnID := r.URL.Query().Get("fnId"); fnID != "" {
+			h, err := m.functionApp(fnID)
+			if err != nil {
+				writeMultiAppError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			if h != nil {
+				h.ServeHTTP(w, r)
+				return
+			}
+		}
+	}
+
+	m.apps[0].ServeHTTP(w, r)
+}
+
+// functionApp returns the app with the given function, preferring the function's
+// fully qualified ID.  Function IDs without an app ID may be shared by several
+// apps, in which case this returns an error rather than guessing.  This returns
+// nil if no app has the function.
+func (m *multiAppHandler) functionApp(fnID string) (*handler, error) {
+	var matches []*handler
+	for _, h := range m.apps {
+		fqID, id := false, false
+		h.l.RLock()
+		for _, f := range h.funcs {
+			fqID = fqID || f.FullyQualifiedID() == fnID
+			id = id || f.ID() == fnID
+		}
+		h.l.RUnlock()
+
+		if fqID {
+			return h, nil
+		}
+		if id {
+			matches = append(matches, h)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		return matches[0], nil
+	}
+	apps := make([]string, len(matches))
+	for i, h := range matches {
+		apps[i] = h.appName
+	}
+	return nil, fmt.Errorf(
+		"function %s exists in apps %s; use the fully qualified function ID or an %s query parameter",
+		fnID,
+		strings.Join(apps, ", "),
+		multiAppQueryParam,
+	)
+}
+
+// syncAll syncs every app out-of-band, each using the app's URL with the app's
+// ID.  Signed in-band sync requests are also handled this way, as a single in-band
+// sync response describes only one app, using the URL from the signed request body
+// rather than the request's URL, which may be an internal address behind a proxy.
+func (m *multiAppHandler) syncAll(w http.ResponseWriter, r *http.Request) {
+	SetBasicResponseHeaders(w)
+	defer func() {
+		_ = r.Body.Close()
+	}()
+
+	sig := r.Header.Get(HeaderKeySignature)
+	var body []byte
+	if sig != "" {
+		max := m.apps[0].MaxBodySize
+		if max == 0 {
+			max = DefaultMaxBodySize
+		}
+
+		var err error
+		body, err = readBody(w, r, max)
+		if err != nil {
+			writeSyncError(w, http.StatusBadRequest, fmt.Errorf("error reading request body"))
+			return
+		}
+
+		if err := m.rejectReplay(r, sig, body); err != nil {
+			if errors.Is(err, errUnauthorized) {
+				writeUnauthorizedResponse(w)
+				return
+			}
+			writeSyncError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	baseURL, err := syncRequestURL(r, body)
+	if err != nil {
+		writeSyncError(w, http.StatusBadRequest, err)
+		return
+	}
+	qp := baseURL.Query()
+	syncID := qp.Get("deployId")
+	qp.Del("deployId")
+	baseURL.RawQuery = qp.Encode()
+
+	var (
+		errs         []error
+		unauthorized int
+	)
+	for _, h := range m.apps {
+		err := m.syncApp(r, h, baseURL, syncID, sig, body)
+		h.recordSync(SyncKindOutOfBand, err)
+		if err == nil {
+			continue
+		}
+
+		h.Logger.Error(
+			"sync error",
+			"error", err,
+			"syncKind", SyncKindOutOfBand,
+		)
+		if errors.Is(err, errUnauthorized) {
+			unauthorized++
+		}
+		errs = append(errs, fmt.Errorf("error syncing app %s: %w", h.appName, err))
+	}
+
+	switch {
+	case unauthorized == len(m.apps):
+		writeUnauthorizedResponse(w)
+	case len(errs) > 0:
+		writeSyncError(w, http.StatusInternalServerError, errors.Join(errs...))
+	default:
+		w.Header().Set(HeaderKeySyncKind, SyncKindOutOfBand)
+	}
+}
+
+// rejectReplay checks a signed sync request for replays using the first app
+// which the signature is valid for, as every app checks the same signature.
+func (m *multiAppHandler) rejectReplay(r *http.Request, sig string, body []byte) error {
+	for _, h := range m.apps {
+		keys := h.signingKeys(r.Context())
+		valid, _, _ := ValidateRequestSignature(
+			r.Context(),
+			sig,
+			keys.Current,
+			keys.Fallback,
+			body,
+			false,
+		)
+		if valid {
+			return h.rejectReplay(r.Context(), r, sig)
+		}
+	}
+	return errUnauthorized
+}
+
+// syncRequestURL returns the URL that the apps are served at:  the URL within a
+// signed in-band sync request's body, or else the request's URL.
+func syncRequestURL(r *http.Request, body []byte) (*url.URL, error) {
+	if len(body) > 0 {
+		var req inBandSynchronizeRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, fmt.Errorf("malformed input: %w", err)
+		}
+		if req.URL != "" {
+			u, err := url.Parse(req.URL)
+			if err != nil {
+				return nil, fmt.Errorf("malformed input: %w", err)
+			}
+			return u, nil
+		}
+	}
+	return requestURL(r)
+}
+
+// syncApp syncs a single app out-of-band after authorizing the request, using the
+// given URL with the app's ID.
+func (m *multiAppHandler) syncApp(
+	r *http.Request,
+	h *handler,
+	baseURL *url.URL,
+	syncID string,
+	sig string,
+	body []byte,
+) error {
+	if sig != "" {
+		keys := h.signingKeys(r.Context())
+		valid, _, _ := ValidateRequestSignature(
+			r.Context(),
+			sig,
+			keys.Current,
+			keys.Fallback,
+			body,
+			false,
+		)
+		if !valid {
+			return errUnauthorized
+		}
+	} else if !h.isUnauthedSyncEnabled() {
+		return errUnauthorized
+	}
+
+	appURL, err := overrideURL(baseURL, h.handlerOpts)
+	if err != nil {
+		return fmt.Errorf("error overriding request URL: %w", err)
+	}
+	qp := appURL.Query()
+	qp.Set(multiAppQueryParam, h.appName)
+	appURL.RawQuery = qp.Encode()
+
+	return h.registerApp(context.WithoutCancel(r.Context()), appURL, syncID, r.Header.Get(HeaderKeyServerKind))
+}
+
+func writeMultiAppError(w http.ResponseWriter, status int, message string) {
+	SetBasicResponseHeaders(w)
+	w.Header().Set(HeaderKeyContentType, "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
+
+func writeSyncError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set(HeaderKeyContentType, "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"code":    syscode.CodeUnknown,
+		"message": err.Error(),
+	})
+}
@@ -0,0 +1,166 @@
+package inngestgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/inngest/inngestgo/internal/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newMultiAppClient(t *testing.T, appID, registerURL string) Client {
+	c, err := NewClient(ClientOpts{
+		AppID:       appID,
+		Dev:         BoolPtr(true),
+		RegisterURL: StrPtr(registerURL),
+	})
+	require.NoError(t, err)
+
+	_, err = CreateFunction(
+		c,
+		FunctionOpts{ID: "fn"},
+		EventTrigger("test/event", nil),
+		func(ctx context.Context, input Input[any]) (any, error) {
+			return appID, nil
+		},
+	)
+	require.NoError(t, err)
+	return c
+}
+
+func TestMultiAppHandler(t *testing.T) {
+	r := require.New(t)
+
+	var (
+		l      sync.Mutex
+		synced = map[string]string{}
+	)
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body types.RegisterRequest
+		_ = json.NewDecoder(req.Body).Decode(&body)
+		l.Lock()
+		synced[body.AppName] = body.URL
+		l.Unlock()
+	}))
+	defer registry.Close()
+
+	h, err := MultiAppHandler([]Client{
+		newMultiAppClient(t, "billing", registry.URL),
+		newMultiAppClient(t, "reports", registry.URL),
+	}, ServeOpts{})
+	r.NoError(err)
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	t.Run("sync all apps", func(t *testing.T) {
+		r := require.New(t)
+		req, err := http.NewRequest(http.MethodPut, server.URL+"/api/inngest", nil)
+		r.NoError(err)
+		resp, err := http.DefaultClient.Do(req)
+		r.NoError(err)
+		_ = resp.Body.Close()
+		r.Equal(http.StatusOK, resp.StatusCode)
+
+		base := server.URL + "/api/inngest"
+		r.Equal(map[string]string{
+			"billing": base + "?appId=billing",
+			"reports": base + "?appId=reports",
+		}, synced)
+	})
+
+	invoke := func(query string) (int, string) {
+		body := marshalRequest(t, createRequest(t, Event{Name: "test/event"}))
+		resp, err := http.Post(server.URL+"?"+query, "application/json", bytes.NewReader(body))
+		r.NoError(err)
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+
+		var out string
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out
+	}
+
+	t.Run("invoke by app ID", func(t *testing.T) {
+		status, out := invoke("appId=reports&fnId=fn")
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, "reports", out)
+	})
+
+	t.Run("invoke by function ID", func(t *testing.T) {
+		status, out := invoke("fnId=reports-fn")
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, "reports", out)
+	})
+
+	t.Run("ambiguous function ID", func(t *testing.T) {
+		status, _ := invoke("fnId=fn")
+		require.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("unknown app", func(t *testing.T) {
+		status, _ := invoke("appId=missing&fnId=fn")
+		require.Equal(t, http.StatusNotFound, status)
+	})
+}
+
+func TestMultiAppHandlerSignedSync(t *testing.T) {
+	setEnvVars(t)
+	r := require.New(t)
+
+	var (
+		l      sync.Mutex
+		synced = map[string]string{}
+	)
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body types.RegisterRequest
+		_ = json.NewDecoder(req.Body).Decode(&body)
+		l.Lock()
+		synced[body.AppName] = body.URL
+		l.Unlock()
+	}))
+	defer registry.Close()
+
+	h, err := MultiAppHandler([]Client{
+		newMultiAppClient(t, "billing", registry.URL),
+		newMultiAppClient(t, "reports", registry.URL),
+	}, ServeOpts{})
+	r.NoError(err)
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	// The apps are synced with the public URL from the signed body, rather than
+	// the internal address that the request was proxied to.
+	body, err := json.Marshal(inBandSynchronizeRequest{URL: "https://example.com/api/inngest"})
+	r.NoError(err)
+	sig, err := Sign(context.Background(), time.Now(), []byte(testKey), body)
+	r.NoError(err)
+
+	req, err := http.NewRequest(http.MethodPut, server.URL+"/internal/inngest", bytes.NewReader(body))
+	r.NoError(err)
+	req.Header.Set(HeaderKeySignature, sig)
+	resp, err := http.DefaultClient.Do(req)
+	r.NoError(err)
+	_ = resp.Body.Close()
+	r.Equal(http.StatusOK, resp.StatusCode)
+	r.Equal(SyncKindOutOfBand, resp.Header.Get(HeaderKeySyncKind))
+
+	r.Equal(map[string]string{
+		"billing": "https://example.com/api/inngest?appId=billing",
+		"reports": "https://example.com/api/inngest?appId=reports",
+	}, synced)
+}
+
+func TestMultiAppHandlerDuplicateApps(t *testing.T) {
+	_, err := MultiAppHandler([]Client{
+		newMultiAppClient(t, "billing", ""),
+		newMultiAppClient(t, "billing", ""),
+	}, ServeOpts{})
+	require.ErrorContains(t, err, "duplicate app ID")
+}
@@ -0,0 +1,188 @@
+package inngestgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	sdkerrors "github.com/inngest/inngestgo/errors"
+	"github.com/inngest/inngestgo/internal/fn"
+	"github.com/inngest/inngestgo/internal/sdkrequest"
+	"github.com/inngest/inngestgo/step"
+)
+
+const (
+	// FunctionCancelledEventName is the event sent by Inngest when a function run
+	// is cancelled.
+	FunctionCancelledEventName = "inngest/function.cancelled"
+)
+
+// CancelHandler is called when a run of a function is cancelled, eg. via a Cancel
+// event, a singleton in cancel mode, or a timeout.  It receives the original run's
+// input and a Cancellation with the cancel reason and the state of all steps that
+// completed before the run was cancelled.  Set this as FunctionOpts.OnCancel.
+//
+// The original run's event data is unmarshalled into T, which is typically the
+// function's event data type.
+type CancelHandler[T any] func(ctx context.Context, input Input[T], c Cancellation) (any, error)
+
+// Cancellation describes a cancelled function run.
+type Cancellation struct {
+	// FunctionID is the fully qualified ID of the function that was cancelled.
+	FunctionID string
+	// RunID is the ID of the run that was cancelled.
+	RunID string
+	// Reason is the reason the run was cancelled.
+	Reason string
+	// Steps is the state of all steps that completed before the run was
+	// cancelled, keyed by hashed step ID.  Use Step to read a step's output by
+	// its ID.
+	Steps map[string]json.RawMessage
+}
+
+// Completed returns whether the step with the given ID completed before the run
+// was cancelled.
+func (c Cancellation) Completed(stepID string) bool {
+	_, ok := c.Steps[sdkrequest.UnhashedOp{ID: stepID}.MustHash()]
+	return ok
+}
+
+// Step unmarshals the output of the step with the given ID into v.  This returns
+// false if the step didn't complete before the run was cancelled, or a StepError
+// if the step failed.
+func (c Cancellation) Step(stepID string, v any) (bool, error) {
+	val, ok := c.Steps[sdkrequest.UnhashedOp{ID: stepID}.MustHash()]
+	if !ok {
+		return false, nil
+	}
+
+	// Step state is typically wrapped within a "data" or "error" field.
+	unwrapped := struct {
+		Data  json.RawMessage `json:"data"`
+		Error json.RawMessage `json:"error"`
+	}{}
+	if err := json.Unmarshal(val, &unwrapped); err == nil {
+		if len(unwrapped.Error) > 0 {
+			stepErr := sdkerrors.StepError{}
+			if err := json.Unmarshal(unwrapped.Error, &stepErr); err != nil {
+				return true, fmt.Errorf("error unmarshalling error for step '%s': %w", stepID, err)
+			}
+			return true, stepErr
+		}
+		if len(unwrapped.Data) > 0 {
+			val = unwrapped.Data
+		}
+	}
+
+	if err := json.Unmarshal(val, v); err != nil {
+		return true, fmt.Errorf("error unmarshalling state for step '%s': %w", stepID, err)
+	}
+	return true, nil
+}
+
+// functionCancelledData is the data within an "inngest/function.cancelled" event.
+type functionCancelledData struct {
+	FunctionID string          `json:"function_id"`
+	RunID      string          `json:"run_id"`
+	Error      runError        `json:"error"`
+	Event      json.RawMessage `json:"event"`
+}
+
+// cancelledRunStepID is the ID of the step which loads the cancelled run's state,
+// so that it's loaded once rather than on every request.
+const cancelledRunStepID = "load-cancelled-run"
+
+// CancelFunction creates the companion function which calls the handler whenever
+// a run of the given function is cancelled.  This is called when the function is
+// registered.
+func (handler CancelHandler[T]) CancelFunction(parent ServableFunction, loadSteps fn.RunStepsLoader) ServableFunction {
+	expr := fmt.Sprintf("event.data.function_id == %s", strconv.Quote(parent.FullyQualifiedID()))
+
+	return servableFunc{
+		appID: companionAppID(parent),
+		fc: FunctionOpts{
+			ID:   fmt.Sprintf("%s-cancel", parent.ID()),
+			Name: fmt.Sprintf("%s (cancel)", parent.Name()),
+		},
+		trigger: EventTrigger(FunctionCancelledEventName, &expr),
+		f: SDKFunction[functionCancelledData](func(ctx context.Context, input Input[functionCancelledData]) (any, error) {
+			data := input.Event.Data
+
+			original, err := originalInput[T](data.Event, input.InputCtx)
+			if err != nil {
+				return nil, err
+			}
+
+			steps, err := step.Run(ctx, cancelledRunStepID, func(ctx context.Context) (map[string]json.RawMessage, error) {
+				return loadSteps(ctx, data.RunID)
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error loading cancelled run state: %w", err)
+			}
+
+			reason := data.Error.Message
+			if reason == "" {
+				reason = "function cancelled"
+			}
+
+			return handler(ctx, original, Cancellation{
+				FunctionID: data.FunctionID,
+				RunID:      data.RunID,
+				Reason:     reason,
+				Steps:      steps,
+			})
+		}),
+	}
+}
+
+// fetchRunSteps loads the state of all completed steps within the given run.
+func (h *handler) fetchRunSteps(ctx context.Context, runID string) (map[string]json.RawMessage, error) {
+	if runID == "" {
+		return map[string]json.RawMessage{}, nil
+	}
+
+	keys := h.signingKeys(ctx)
+	resp, err := fetchWithAuthFallback(
+		func() (*http.Request, error) {
+			req, err := http.NewRequestWithContext(
+				ctx,
+				http.MethodGet,
+				fmt.Sprintf("%s/v0/runs/%s/actions", h.GetAPIBaseURL(), runID),
+				nil,
+			)
+			if err != nil {
+				return nil, err
+			}
+			SetBasicRequestHeaders(req)
+			if h.GetEnv() != "" {
+				req.Header.Add(HeaderKeyEnv, h.GetEnv())
+			}
+			return req, nil
+		},
+		keys.Current,
+		keys.Fallback,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	byt, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+	if resp.StatusCode > 299 {
+		return nil, fmt.Errorf("error fetching run state (%d): %s", resp.StatusCode, byt)
+	}
+
+	steps := map[string]json.RawMessage{}
+	if err := json.Unmarshal(byt, &steps); err != nil {
+		return nil, fmt.Errorf("error unmarshalling run state: %w", err)
+	}
+	return steps, nil
+}
@@ -0,0 +1,134 @@
+package inngestgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/inngest/inngestgo/internal/sdkrequest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnCancel(t *testing.T) {
+	type OrderData struct {
+		OrderID string `json:"order_id"`
+	}
+
+	r := require.New(t)
+
+	reserved := sdkrequest.UnhashedOp{ID: "reserve-stock"}.MustHash()
+	failed := sdkrequest.UnhashedOp{ID: "charge"}.MustHash()
+
+	var (
+		requestedPath string
+		requests      int
+	)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPath = r.URL.Path
+		requests++
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			reserved: map[string]any{"data": map[string]any{"reservation": "res_1"}},
+			failed:   map[string]any{"error": map[string]any{"name": "Error", "message": "card declined"}},
+		})
+	}))
+	defer api.Close()
+
+	c, err := NewClient(ClientOpts{
+		AppID:      "app",
+		Dev:        Ptr(true),
+		APIBaseURL: Ptr(api.URL),
+	})
+	r.NoError(err)
+
+	var (
+		gotInput  Input[OrderData]
+		gotCancel Cancellation
+	)
+	_, err = CreateFunction(
+		c,
+		FunctionOpts{
+			ID: "fulfil",
+			OnCancel: CancelHandler[OrderData](func(ctx context.Context, input Input[OrderData], c Cancellation) (any, error) {
+				gotInput = input
+				gotCancel = c
+				return nil, nil
+			}),
+		},
+		EventTrigger("order/created", nil),
+		func(ctx context.Context, input Input[OrderData]) (any, error) {
+			return nil, nil
+		},
+	)
+	r.NoError(err)
+
+	fn := c.(*apiClient).h.getServableFunctionBySlug("app-fulfil-cancel")
+	r.NotNil(fn)
+	r.Equal(FunctionCancelledEventName, fn.Trigger().Triggers()[0].Event)
+	r.Equal(`event.data.function_id == "app-fulfil"`, *fn.Trigger().Triggers()[0].Expression)
+
+	call := func(steps map[string]json.RawMessage) *httptest.ResponseRecorder {
+		body, err := json.Marshal(sdkrequest.Request{
+			Event: []byte(`{
+				"name": "inngest/function.cancelled",
+				"data": {
+					"function_id": "app-fulfil",
+					"run_id": "01ARZ3NDEKTSV4RRFFQ69G5FAV",
+					"error": {"name": "Error", "message": "function cancelled"},
+					"event": {"name": "order/created", "data": {"order_id": "ord_1"}}
+				}
+			}`),
+			Steps: steps,
+			CallCtx: sdkrequest.CallCtx{
+				FunctionID: uuid.New(),
+				RunID:      "01ARZ3NDEKTSV4RRFFQ69G5FAW",
+			},
+		})
+		r.NoError(err)
+
+		req := httptest.NewRequest(http.MethodPost, "/?fnId=app-fulfil-cancel", bytes.NewReader(body))
+		rr := httptest.NewRecorder()
+		c.Serve().ServeHTTP(rr, req)
+		return rr
+	}
+
+	// The cancelled run's state is loaded within a step, so that replays use
+	// the same state without calling the API again.
+	rr := call(map[string]json.RawMessage{})
+	r.Equal(206, rr.Code, rr.Body.String())
+	r.Equal("/v0/runs/01ARZ3NDEKTSV4RRFFQ69G5FAV/actions", requestedPath)
+	r.Equal(1, requests)
+
+	var ops []sdkrequest.GeneratorOpcode
+	r.NoError(json.Unmarshal(rr.Body.Bytes(), &ops))
+	r.Len(ops, 1)
+	r.Equal(sdkrequest.UnhashedOp{ID: cancelledRunStepID}.MustHash(), ops[0].ID)
+
+	state, err := json.Marshal(map[string]json.RawMessage{"data": ops[0].Data})
+	r.NoError(err)
+	rr = call(map[string]json.RawMessage{ops[0].ID: state})
+	r.Equal(http.StatusOK, rr.Code, rr.Body.String())
+	r.Equal(1, requests)
+
+	r.Equal("ord_1", gotInput.Event.Data.OrderID)
+	r.Equal("app-fulfil", gotCancel.FunctionID)
+	r.Equal("01ARZ3NDEKTSV4RRFFQ69G5FAV", gotCancel.RunID)
+	r.Equal("function cancelled", gotCancel.Reason)
+
+	var reservation struct {
+		Reservation string `json:"reservation"`
+	}
+	ok, err := gotCancel.Step("reserve-stock", &reservation)
+	r.True(ok)
+	r.NoError(err)
+	r.Equal("res_1", reservation.Reservation)
+
+	ok, err = gotCancel.Step("charge", &reservation)
+	r.True(ok)
+	r.ErrorContains(err, "card declined")
+
+	r.False(gotCancel.Completed("ship"))
+}
@@ -0,0 +1,123 @@
+package inngestgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// FunctionFailedEventName is the event sent by Inngest when a function run
+	// permanently fails.
+	FunctionFailedEventName = "inngest/function.failed"
+)
+
+// FailureHandler is called when a run of a function permanently fails, after all
+// retries have been exhausted.  It receives the original run's input and a
+// FunctionFailedError describing the failure.  Set this as FunctionOpts.OnFailure:
+//
+//	inngestgo.CreateFunction(
+//		client,
+//		inngestgo.FunctionOpts{
+//			ID: "send-welcome-email",
+//			OnFailure: inngestgo.FailureHandler[SignupData](func(ctx context.Context, input inngestgo.Input[SignupData], err error) (any, error) {
+//				// Clean up, alert, etc.
+//				return nil, nil
+//			}),
+//		},
+//		inngestgo.EventTrigger("user/signed.up", nil),
+//		handler,
+//	)
+//
+// The original run's event data is unmarshalled into T, which is typically the
+// function's event data type.
+type FailureHandler[T any] func(ctx context.Context, input Input[T], err error) (any, error)
+
+// FunctionFailedError describes why a function run permanently failed.  This is
+// passed to FailureHandler as its error.
+type FunctionFailedError struct {
+	// FunctionID is the fully qualified ID of the function that failed.
+	FunctionID string `json:"function_id"`
+	// RunID is the ID of the run that failed.
+	RunID string `json:"run_id"`
+	// Name is the name of the error that failed the run.
+	Name string `json:"name"`
+	// Message is the message of the error that failed the run.
+	Message string `json:"message"`
+	// Stack is the stack trace of the error, if any.
+	Stack string `json:"stack,omitempty"`
+}
+
+func (e FunctionFailedError) Error() string {
+	if e.Name == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Name, e.Message)
+}
+
+// runError is the serialized error within function lifecycle events.
+type runError struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+	Stack   string `json:"stack,omitempty"`
+}
+
+// functionFailedData is the data within an "inngest/function.failed" event.
+type functionFailedData struct {
+	FunctionID string          `json:"function_id"`
+	RunID      string          `json:"run_id"`
+	Error      runError        `json:"error"`
+	Event      json.RawMessage `json:"event"`
+}
+
+// FailureFunction creates the companion function which calls the handler whenever
+// the given function fails.  This is called when the function is registered.
+func (handler FailureHandler[T]) FailureFunction(parent ServableFunction) ServableFunction {
+	expr := fmt.Sprintf("event.data.function_id == %s", strconv.Quote(parent.FullyQualifiedID()))
+
+	return servableFunc{
+		appID: companionAppID(parent),
+		fc: FunctionOpts{
+			ID:   fmt.Sprintf("%s-failure", parent.ID()),
+			Name: fmt.Sprintf("%s (failure)", parent.Name()),
+		},
+		trigger: EventTrigger(FunctionFailedEventName, &expr),
+		f: SDKFunction[functionFailedData](func(ctx context.Context, input Input[functionFailedData]) (any, error) {
+			data := input.Event.Data
+
+			original, err := originalInput[T](data.Event, input.InputCtx)
+			if err != nil {
+				return nil, err
+			}
+
+			return handler(ctx, original, FunctionFailedError{
+				FunctionID: data.FunctionID,
+				RunID:      data.RunID,
+				Name:       data.Error.Name,
+				Message:    data.Error.Message,
+				Stack:      data.Error.Stack,
+			})
+		}),
+	}
+}
+
+// companionAppID returns the app ID of the given function, which its companion
+// functions are registered with.
+func companionAppID(parent ServableFunction) string {
+	return strings.TrimSuffix(parent.FullyQualifiedID(), "-"+parent.ID())
+}
+
+// originalInput creates the input for the original run from the triggering event
+// embedded within function lifecycle events.
+func originalInput[T any](evt json.RawMessage, ctx InputCtx) (Input[T], error) {
+	input := Input[T]{InputCtx: ctx}
+	if len(evt) > 0 {
+		if err := json.Unmarshal(evt, &input.Event); err != nil {
+			return input, NoRetryError(fmt.Errorf("error unmarshalling original event: %w", err))
+		}
+	}
+	input.Events = []GenericEvent[T]{input.Event}
+	return input, nil
+}
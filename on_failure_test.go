@@ -0,0 +1,155 @@
+package inngestgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/inngest/inngestgo/internal/sdkrequest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnFailure(t *testing.T) {
+	type SignupData struct {
+		Email string `json:"email"`
+	}
+
+	t.Run("registers a companion function", func(t *testing.T) {
+		r := require.New(t)
+
+		c, err := NewClient(ClientOpts{AppID: "app", Dev: Ptr(true)})
+		r.NoError(err)
+
+		_, err = CreateFunction(
+			c,
+			FunctionOpts{
+				ID:   "welcome",
+				Name: "Welcome",
+				OnFailure: FailureHandler[SignupData](func(ctx context.Context, input Input[SignupData], err error) (any, error) {
+					return nil, nil
+				}),
+			},
+			EventTrigger("user/signed.up", nil),
+			func(ctx context.Context, input Input[SignupData]) (any, error) {
+				return nil, nil
+			},
+		)
+		r.NoError(err)
+
+		h := c.(*apiClient).h
+		configs, err := createFunctionConfigs(h.appName, h.GetFunctions(), url.URL{Scheme: "http", Host: "localhost"}, false)
+		r.NoError(err)
+		r.Len(configs, 2)
+
+		var found bool
+		for _, cfg := range configs {
+			if cfg.Slug != "app-welcome-failure" {
+				continue
+			}
+			found = true
+			r.Equal("Welcome (failure)", cfg.Name)
+			r.Len(cfg.Triggers, 1)
+			r.Equal(FunctionFailedEventName, cfg.Triggers[0].Event)
+			r.Equal(`event.data.function_id == "app-welcome"`, *cfg.Triggers[0].Expression)
+		}
+		r.True(found, "failure function not synced")
+	})
+
+	t.Run("calls the handler with the original input", func(t *testing.T) {
+		r := require.New(t)
+
+		c, err := NewClient(ClientOpts{AppID: "app", Dev: Ptr(true)})
+		r.NoError(err)
+
+		var (
+			gotInput Input[SignupData]
+			gotErr   error
+		)
+		_, err = CreateFunction(
+			c,
+			FunctionOpts{
+				ID: "welcome",
+				OnFailure: FailureHandler[SignupData](func(ctx context.Context, input Input[SignupData], err error) (any, error) {
+					gotInput = input
+					gotErr = err
+					return "handled", nil
+				}),
+			},
+			EventTrigger("user/signed.up", nil),
+			func(ctx context.Context, input Input[SignupData]) (any, error) {
+				return nil, nil
+			},
+		)
+		r.NoError(err)
+
+		body, err := json.Marshal(sdkrequest.Request{
+			Event: []byte(`{
+				"name": "inngest/function.failed",
+				"data": {
+					"function_id": "app-welcome",
+					"run_id": "01ARZ3NDEKTSV4RRFFQ69G5FAV",
+					"error": {"name": "Error", "message": "boom"},
+					"event": {"name": "user/signed.up", "data": {"email": "a@example.com"}}
+				}
+			}`),
+			Steps: map[string]json.RawMessage{},
+			CallCtx: sdkrequest.CallCtx{
+				FunctionID: uuid.New(),
+				RunID:      "01ARZ3NDEKTSV4RRFFQ69G5FAW",
+			},
+		})
+		r.NoError(err)
+
+		req := httptest.NewRequest(http.MethodPost, "/?fnId=app-welcome-failure", bytes.NewReader(body))
+		rr := httptest.NewRecorder()
+		c.Serve().ServeHTTP(rr, req)
+
+		r.Equal(http.StatusOK, rr.Code, rr.Body.String())
+		r.JSONEq(`"handled"`, rr.Body.String())
+		r.Equal("user/signed.up", gotInput.Event.Name)
+		r.Equal("a@example.com", gotInput.Event.Data.Email)
+		r.Len(gotInput.Events, 1)
+
+		var failed FunctionFailedError
+		r.True(errors.As(gotErr, &failed))
+		r.Equal("app-welcome", failed.FunctionID)
+		r.Equal("01ARZ3NDEKTSV4RRFFQ69G5FAV", failed.RunID)
+		r.Equal("Error: boom", failed.Error())
+	})
+
+	t.Run("registers companions with the handler", func(t *testing.T) {
+		r := require.New(t)
+
+		c, err := NewClient(ClientOpts{AppID: "app", Dev: Ptr(true)})
+		r.NoError(err)
+
+		// Functions registered directly, rather than via CreateFunction, also
+		// register their companions.
+		h := newHandler(c, handlerOpts{})
+		h.Register(servableFunc{
+			appID: "app",
+			fc: FunctionOpts{
+				ID: "welcome",
+				OnFailure: FailureHandler[SignupData](func(ctx context.Context, input Input[SignupData], err error) (any, error) {
+					return nil, nil
+				}),
+			},
+			trigger: EventTrigger("user/signed.up", nil),
+			f: SDKFunction[SignupData](func(ctx context.Context, input Input[SignupData]) (any, error) {
+				return nil, nil
+			}),
+		})
+
+		var ids []string
+		for _, f := range h.GetFunctions() {
+			ids = append(ids, f.FullyQualifiedID())
+		}
+		r.ElementsMatch([]string{"app-welcome", "app-welcome-failure"}, ids)
+	})
+}
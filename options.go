@@ -50,3 +50,18 @@ func overrideURL(u *url.URL, hOpts handlerOpts) (*url.URL, error) {
 
 	return url.Parse(rawURL)
 }
+
+// configuredURL returns the URL that the app is served at from the configured
+// origin and path, for syncing without an incoming request.
+func configuredURL(hOpts handlerOpts) (*url.URL, error) {
+	origin := serveOriginOverride(hOpts)
+	if origin == nil {
+		return nil, fmt.Errorf("no serve URL configured: set ClientOpts.URL, ServeOpts.Origin or INNGEST_SERVE_HOST")
+	}
+
+	path := ""
+	if override := servePathOverride(hOpts); override != nil {
+		path = *override
+	}
+	return url.Parse(*origin + path)
+}
@@ -0,0 +1,107 @@
+package outbox
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-memory Store, useful for testing code which enqueues
+// events.  Events within a MemoryStore aren't persisted or transactional.
+type MemoryStore struct {
+	l       sync.Mutex
+	records map[string]*memoryRecord
+}
+
+type memoryRecord struct {
+	Record
+	next      time.Time
+	lastError string
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: map[string]*memoryRecord{}}
+}
+
+// Enqueue adds the given events to the store, returning their IDs.  Events with an
+// ID which is already enqueued are ignored.
+func (m *MemoryStore) Enqueue(ctx context.Context, events ...any) ([]string, error) {
+	now := time.Now()
+	records := make([]Record, len(events))
+	for i, evt := range events {
+		rec, err := NewRecord(evt, now)
+		if err != nil {
+			return nil, err
+		}
+		records[i] = rec
+	}
+
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	ids := make([]string, len(records))
+	for i, rec := range records {
+		ids[i] = rec.ID
+		if _, ok := m.records[rec.ID]; ok {
+			continue
+		}
+		m.records[rec.ID] = &memoryRecord{Record: rec, next: now}
+	}
+	return ids, nil
+}
+
+// Len returns the number of events within the store.
+func (m *MemoryStore) Len() int {
+	m.l.Lock()
+	defer m.l.Unlock()
+	return len(m.records)
+}
+
+// Pending returns up to limit records which are due to be sent, oldest first.
+func (m *MemoryStore) Pending(ctx context.Context, now time.Time, limit int) ([]Record, error) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	result := []Record{}
+	for _, r := range m.records {
+		if !r.next.After(now) {
+			result = append(result, r.Record)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].ID < result[j].ID
+		}
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// Delete removes the given records.
+func (m *MemoryStore) Delete(ctx context.Context, ids []string) error {
+	m.l.Lock()
+	defer m.l.Unlock()
+	for _, id := range ids {
+		delete(m.records, id)
+	}
+	return nil
+}
+
+// Retry records a failed attempt to send the given records.
+func (m *MemoryStore) Retry(ctx context.Context, ids []string, at time.Time, cause string) error {
+	m.l.Lock()
+	defer m.l.Unlock()
+	for _, id := range ids {
+		if r, ok := m.records[id]; ok {
+			r.Attempts++
+			r.next = at
+			r.lastError = cause
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// Package outbox implements the transactional outbox pattern for sending events.
+//
+// Client.Send makes an HTTP request directly, so sending events alongside database
+// writes may lose events if the send fails after the transaction commits, or send
+// phantom events if the transaction rolls back after sending.  Instead, events are
+// written to an outbox table within the same transaction as your own writes:
+//
+//	store := outbox.NewSQLStore(db, outbox.SQLStoreOpts{})
+//
+//	tx, _ := db.BeginTx(ctx, nil)
+//	// ... your own writes ...
+//	_, err := store.Enqueue(ctx, tx, inngestgo.Event{Name: "user/created", Data: data})
+//	_ = tx.Commit()
+//
+// A Relay then drains the outbox, sending events to Inngest:
+//
+//	relay, _ := outbox.NewRelay(outbox.RelayOpts{Client: client, Store: store})
+//	go relay.Run(ctx)
+//
+// Every event is assigned an ID when enqueued.  Inngest deduplicates events by ID,
+// so events which are sent more than once (eg. when a send succeeds but removing
+// the events from the outbox fails, or when running many relays) only trigger
+// functions once.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// Record is a single event stored within the outbox.
+type Record struct {
+	// ID is the event's ID, used by Inngest for deduplication.
+	ID string
+	// Event is the JSON-encoded event.
+	Event json.RawMessage
+	// Attempts is the number of failed attempts to send the event.
+	Attempts int
+	// CreatedAt is the time the event was enqueued.
+	CreatedAt time.Time
+}
+
+// Store stores events which are yet to be sent.  Store is implemented by SQLStore
+// and MemoryStore, and may be implemented for any other storage.
+type Store interface {
+	// Pending returns up to limit records which are due to be sent at the given
+	// time, oldest first.
+	Pending(ctx context.Context, now time.Time, limit int) ([]Record, error)
+	// Delete removes the given records, once sent.
+	Delete(ctx context.Context, ids []string) error
+	// Retry records a failed attempt to send the given records, incrementing their
+	// attempts and scheduling them to be sent again at the given time.
+	Retry(ctx context.Context, ids []string, at time.Time, cause string) error
+}
+
+type validatable interface {
+	Validate() error
+}
+
+// NewRecord validates and encodes the given event, assigning an ID to the event if
+// it has none.  This is used by Store implementations when enqueueing events.
+func NewRecord(evt any, now time.Time) (Record, error) {
+	if v, ok := evt.(validatable); ok {
+		if err := v.Validate(); err != nil {
+			return Record{}, fmt.Errorf("error validating event: %w", err)
+		}
+	}
+
+	byt, err := json.Marshal(evt)
+	if err != nil {
+		return Record{}, fmt.Errorf("error marshalling event to json: %w", err)
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(byt, &fields); err != nil {
+		return Record{}, fmt.Errorf("event must be a JSON object: %w", err)
+	}
+	if _, ok := fields["name"]; !ok {
+		return Record{}, fmt.Errorf("event name must be present")
+	}
+
+	var id string
+	if raw, ok := fields["id"]; ok {
+		if err := json.Unmarshal(raw, &id); err != nil {
+			return Record{}, fmt.Errorf("event id must be a string: %w", err)
+		}
+	}
+	if id == "" {
+		id = ulid.Make().String()
+		fields["id"], _ = json.Marshal(id)
+		if byt, err = json.Marshal(fields); err != nil {
+			return Record{}, fmt.Errorf("error marshalling event to json: %w", err)
+		}
+	}
+
+	return Record{
+		ID:        id,
+		Event:     byt,
+		CreatedAt: now,
+	}, nil
+}
@@ -0,0 +1,198 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/inngest/inngestgo/internal/logger"
+)
+
+const (
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 100
+	defaultMaxBackoff   = 5 * time.Minute
+)
+
+// Sender sends events to Inngest.  This is implemented by inngestgo.Client.
+type Sender interface {
+	SendMany(ctx context.Context, e []any) ([]string, error)
+}
+
+// RelayOpts configures a Relay.
+type RelayOpts struct {
+	// Client sends events to Inngest.  This is typically an inngestgo.Client.
+	Client Sender
+	// Store is the outbox to drain.
+	Store Store
+
+	// PollInterval is how often the relay checks the store for new events once it
+	// has been drained, defaulting to one second.
+	PollInterval time.Duration
+	// BatchSize is the maximum number of events sent in a single request,
+	// defaulting to 100.
+	BatchSize int
+	// MaxAttempts is the maximum number of attempts to send each event.  Once
+	// exceeded, events are logged and removed from the store.  Zero retries
+	// events indefinitely.
+	MaxAttempts int
+	// Backoff returns the delay before retrying events which have failed the
+	// given number of times.  This defaults to exponential backoff from one
+	// second, capped at five minutes.
+	Backoff func(attempts int) time.Duration
+
+	// Logger is the logger used for failures, defaulting to the SDK's logger.
+	Logger *slog.Logger
+}
+
+// Relay sends events from a Store to Inngest.
+type Relay struct {
+	opts RelayOpts
+	now  func() time.Time
+}
+
+// NewRelay returns a Relay for the given options.
+func NewRelay(opts RelayOpts) (*Relay, error) {
+	if opts.Client == nil {
+		return nil, fmt.Errorf("a client is required")
+	}
+	if opts.Store == nil {
+		return nil, fmt.Errorf("a store is required")
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	if opts.Backoff == nil {
+		opts.Backoff = defaultBackoff
+	}
+	if opts.Logger == nil {
+		opts.Logger = logger.Default()
+	}
+	return &Relay{opts: opts, now: time.Now}, nil
+}
+
+// Run drains the store until the given context is cancelled, polling for new
+// events every PollInterval.  Failures are logged and retried, so Run only
+// returns once the context is done.
+func (r *Relay) Run(ctx context.Context) error {
+	t := time.NewTicker(r.opts.PollInterval)
+	defer t.Stop()
+
+	for {
+		if _, err := r.Flush(ctx); err != nil && ctx.Err() == nil {
+			r.opts.Logger.Error("error relaying outbox events", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// Flush sends all events which are currently due, returning the number of events
+// sent.  Events which fail to send are scheduled for retry and do not cause an
+// error;  errors are only returned if the store fails.
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	sent := 0
+	for {
+		records, err := r.opts.Store.Pending(ctx, r.now(), r.opts.BatchSize)
+		if err != nil {
+			return sent, err
+		}
+		if len(records) == 0 {
+			return sent, nil
+		}
+
+		ok, err := r.send(ctx, records)
+		sent += ok
+		if err != nil {
+			return sent, err
+		}
+		if ok == 0 || len(records) < r.opts.BatchSize {
+			// Either the batch failed and has been rescheduled, or there is
+			// nothing left to send.
+			return sent, nil
+		}
+	}
+}
+
+// send sends a batch of records, returning the number of events sent.
+func (r *Relay) send(ctx context.Context, records []Record) (int, error) {
+	ids := make([]string, len(records))
+	events := make([]any, len(records))
+	for i, rec := range records {
+		ids[i] = rec.ID
+		events[i] = json.RawMessage(rec.Event)
+	}
+
+	if _, sendErr := r.opts.Client.SendMany(ctx, events); sendErr != nil {
+		return 0, r.retry(ctx, records, sendErr)
+	}
+
+	// The events have been sent.  If deleting fails the events will be sent
+	// again, which Inngest deduplicates using the events' IDs.
+	if err := r.opts.Store.Delete(ctx, ids); err != nil {
+		return 0, err
+	}
+	return len(records), nil
+}
+
+// retry schedules records which failed to send, discarding records which have
+// exceeded the maximum number of attempts.
+func (r *Relay) retry(ctx context.Context, records []Record, cause error) error {
+	var (
+		discard = []string{}
+		retry   = map[time.Time][]string{}
+		now     = r.now()
+	)
+
+	for _, rec := range records {
+		attempts := rec.Attempts + 1
+		if r.opts.MaxAttempts > 0 && attempts >= r.opts.MaxAttempts {
+			discard = append(discard, rec.ID)
+			continue
+		}
+		at := now.Add(r.opts.Backoff(attempts))
+		retry[at] = append(retry[at], rec.ID)
+	}
+
+	r.opts.Logger.Warn(
+		"error sending outbox events",
+		"error", cause,
+		"events", len(records),
+		"discarded", len(discard),
+	)
+
+	if len(discard) > 0 {
+		r.opts.Logger.Error(
+			"discarding outbox events after max attempts",
+			"error", cause,
+			"event_ids", discard,
+		)
+		if err := r.opts.Store.Delete(ctx, discard); err != nil {
+			return err
+		}
+	}
+	for at, ids := range retry {
+		if err := r.opts.Store.Retry(ctx, ids, at, cause.Error()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func defaultBackoff(attempts int) time.Duration {
+	if attempts > 20 {
+		return defaultMaxBackoff
+	}
+	d := time.Second * time.Duration(math.Pow(2, float64(attempts-1)))
+	return min(d, defaultMaxBackoff)
+}
@@ -0,0 +1,181 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/inngest/inngestgo"
+	"github.com/stretchr/testify/require"
+)
+
+type sender struct {
+	l      sync.Mutex
+	err    error
+	events []map[string]any
+}
+
+func (s *sender) SendMany(ctx context.Context, e []any) ([]string, error) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	ids := []string{}
+	for _, evt := range e {
+		m := map[string]any{}
+		if err := json.Unmarshal(evt.(json.RawMessage), &m); err != nil {
+			return nil, err
+		}
+		s.events = append(s.events, m)
+		ids = append(ids, m["id"].(string))
+	}
+	return ids, nil
+}
+
+func newRelay(t *testing.T, opts RelayOpts) *Relay {
+	opts.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	r, err := NewRelay(opts)
+	require.NoError(t, err)
+	return r
+}
+
+func TestNewRecord(t *testing.T) {
+	r := require.New(t)
+
+	rec, err := NewRecord(inngestgo.Event{Name: "user/created", Data: map[string]any{"id": 1}}, time.Now())
+	r.NoError(err)
+	r.NotEmpty(rec.ID)
+
+	evt := map[string]any{}
+	r.NoError(json.Unmarshal(rec.Event, &evt))
+	r.Equal(rec.ID, evt["id"])
+	r.Equal("user/created", evt["name"])
+
+	// Existing IDs are kept.
+	rec, err = NewRecord(inngestgo.Event{ID: inngestgo.StrPtr("evt-1"), Name: "user/created"}, time.Now())
+	r.NoError(err)
+	r.Equal("evt-1", rec.ID)
+
+	_, err = NewRecord(inngestgo.Event{}, time.Now())
+	r.Error(err)
+	_, err = NewRecord("nope", time.Now())
+	r.Error(err)
+}
+
+func TestRelayFlush(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	store := NewMemoryStore()
+	for i := 0; i < 5; i++ {
+		_, err := store.Enqueue(ctx, inngestgo.Event{Name: "test/event", Data: map[string]any{"i": i}})
+		r.NoError(err)
+	}
+
+	s := &sender{}
+	relay := newRelay(t, RelayOpts{Client: s, Store: store, BatchSize: 2})
+
+	n, err := relay.Flush(ctx)
+	r.NoError(err)
+	r.Equal(5, n)
+	r.Equal(0, store.Len())
+	r.Len(s.events, 5)
+	for _, evt := range s.events {
+		r.NotEmpty(evt["id"])
+	}
+}
+
+func TestRelayRetry(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	store := NewMemoryStore()
+	ids, err := store.Enqueue(ctx, inngestgo.Event{Name: "test/event"})
+	r.NoError(err)
+
+	now := time.Now()
+	s := &sender{err: fmt.Errorf("unavailable")}
+	relay := newRelay(t, RelayOpts{
+		Client:      s,
+		Store:       store,
+		MaxAttempts: 3,
+		Backoff:     func(int) time.Duration { return time.Minute },
+	})
+	relay.now = func() time.Time { return now }
+
+	n, err := relay.Flush(ctx)
+	r.NoError(err)
+	r.Equal(0, n)
+	r.Equal(1, store.Len())
+
+	// The event isn't retried until the backoff has passed.
+	pending, err := store.Pending(ctx, now, 10)
+	r.NoError(err)
+	r.Empty(pending)
+
+	now = now.Add(time.Minute)
+	pending, err = store.Pending(ctx, now, 10)
+	r.NoError(err)
+	r.Len(pending, 1)
+	r.Equal(1, pending[0].Attempts)
+
+	// Succeeding sends the event with the same ID.
+	s.err = nil
+	n, err = relay.Flush(ctx)
+	r.NoError(err)
+	r.Equal(1, n)
+	r.Equal(ids[0], s.events[0]["id"])
+	r.Equal(0, store.Len())
+}
+
+func TestRelayMaxAttempts(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	store := NewMemoryStore()
+	_, err := store.Enqueue(ctx, inngestgo.Event{Name: "test/event"})
+	r.NoError(err)
+
+	now := time.Now()
+	relay := newRelay(t, RelayOpts{
+		Client:      &sender{err: fmt.Errorf("unavailable")},
+		Store:       store,
+		MaxAttempts: 2,
+		Backoff:     func(int) time.Duration { return 0 },
+	})
+	relay.now = func() time.Time { return now }
+
+	_, err = relay.Flush(ctx)
+	r.NoError(err)
+	r.Equal(1, store.Len())
+
+	_, err = relay.Flush(ctx)
+	r.NoError(err)
+	r.Equal(0, store.Len())
+}
+
+func TestRelayRun(t *testing.T) {
+	r := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := NewMemoryStore()
+	s := &sender{}
+	relay := newRelay(t, RelayOpts{Client: s, Store: store, PollInterval: 10 * time.Millisecond})
+
+	done := make(chan error)
+	go func() { done <- relay.Run(ctx) }()
+
+	_, err := store.Enqueue(ctx, inngestgo.Event{Name: "test/event"})
+	r.NoError(err)
+
+	r.Eventually(func() bool { return store.Len() == 0 }, time.Second, 10*time.Millisecond)
+	cancel()
+	r.ErrorIs(<-done, context.Canceled)
+}
@@ -0,0 +1,181 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTable is the outbox table used when SQLStoreOpts.Table is empty.
+const DefaultTable = "inngest_outbox"
+
+// Placeholder is the bind parameter style used by a SQL driver.
+type Placeholder int
+
+const (
+	// PlaceholderQuestion uses "?" parameters, as used by MySQL and SQLite.
+	PlaceholderQuestion Placeholder = iota
+	// PlaceholderDollar uses "$1" parameters, as used by Postgres.
+	PlaceholderDollar
+)
+
+// SQLStoreOpts configures a SQLStore.
+type SQLStoreOpts struct {
+	// Table is the name of the outbox table, defaulting to DefaultTable.
+	Table string
+	// Placeholder is the bind parameter style used by the database driver,
+	// defaulting to PlaceholderQuestion.
+	Placeholder Placeholder
+}
+
+// SQLStore is a Store backed by a database/sql table.  The table must be created
+// ahead of time; see Schema.
+//
+// Times are stored as unix milliseconds so that the store works with any driver.
+type SQLStore struct {
+	db   *sql.DB
+	opts SQLStoreOpts
+}
+
+// NewSQLStore returns a SQLStore using the given database.
+func NewSQLStore(db *sql.DB, opts SQLStoreOpts) *SQLStore {
+	if opts.Table == "" {
+		opts.Table = DefaultTable
+	}
+	return &SQLStore{db: db, opts: opts}
+}
+
+// Schema returns a CREATE TABLE statement for the store's outbox table, which
+// is portable across common databases.  You may create the table yourself using
+// any equivalent column types.
+func (s *SQLStore) Schema() string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id VARCHAR(255) NOT NULL PRIMARY KEY,
+	event TEXT NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT,
+	next_attempt_at BIGINT NOT NULL,
+	created_at BIGINT NOT NULL
+)`, s.opts.Table)
+}
+
+// Enqueue writes the given events to the outbox within the given transaction,
+// returning the events' IDs.  The events are sent by a Relay once the transaction
+// commits, and are discarded if the transaction rolls back.
+func (s *SQLStore) Enqueue(ctx context.Context, tx *sql.Tx, events ...any) ([]string, error) {
+	now := time.Now()
+	query := fmt.Sprintf(
+		"INSERT INTO %s (id, event, attempts, next_attempt_at, created_at) VALUES (%s)",
+		s.opts.Table,
+		s.params(0, 5),
+	)
+
+	ids := make([]string, len(events))
+	for i, evt := range events {
+		rec, err := NewRecord(evt, now)
+		if err != nil {
+			return nil, err
+		}
+		ms := now.UnixMilli()
+		if _, err := tx.ExecContext(ctx, query, rec.ID, string(rec.Event), 0, ms, ms); err != nil {
+			return nil, fmt.Errorf("error writing event to outbox: %w", err)
+		}
+		ids[i] = rec.ID
+	}
+	return ids, nil
+}
+
+// Pending returns up to limit records which are due to be sent, oldest first.
+func (s *SQLStore) Pending(ctx context.Context, now time.Time, limit int) ([]Record, error) {
+	query := fmt.Sprintf(
+		"SELECT id, event, attempts, created_at FROM %s WHERE next_attempt_at <= %s ORDER BY created_at, id LIMIT %d",
+		s.opts.Table,
+		s.params(0, 1),
+		limit,
+	)
+	rows, err := s.db.QueryContext(ctx, query, now.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("error querying outbox: %w", err)
+	}
+	defer rows.Close()
+
+	result := []Record{}
+	for rows.Next() {
+		var (
+			rec     Record
+			event   string
+			created int64
+		)
+		if err := rows.Scan(&rec.ID, &event, &rec.Attempts, &created); err != nil {
+			return nil, fmt.Errorf("error reading outbox: %w", err)
+		}
+		rec.Event = []byte(event)
+		rec.CreatedAt = time.UnixMilli(created)
+		result = append(result, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading outbox: %w", err)
+	}
+	return result, nil
+}
+
+// Delete removes the given records.
+func (s *SQLStore) Delete(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	query := fmt.Sprintf(
+		"DELETE FROM %s WHERE id IN (%s)",
+		s.opts.Table,
+		s.params(0, len(ids)),
+	)
+	if _, err := s.db.ExecContext(ctx, query, anys(ids)...); err != nil {
+		return fmt.Errorf("error deleting from outbox: %w", err)
+	}
+	return nil
+}
+
+// Retry records a failed attempt to send the given records.
+func (s *SQLStore) Retry(ctx context.Context, ids []string, at time.Time, cause string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	query := fmt.Sprintf(
+		"UPDATE %s SET attempts = attempts + 1, next_attempt_at = %s, last_error = %s WHERE id IN (%s)",
+		s.opts.Table,
+		s.params(0, 1),
+		s.params(1, 1),
+		s.params(2, len(ids)),
+	)
+	args := append([]any{at.UnixMilli(), cause}, anys(ids)...)
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("error updating outbox: %w", err)
+	}
+	return nil
+}
+
+// params returns n comma separated bind parameters, starting after the given
+// number of preceding parameters.
+func (s *SQLStore) params(offset, n int) string {
+	p := make([]string, n)
+	for i := range p {
+		switch s.opts.Placeholder {
+		case PlaceholderDollar:
+			p[i] = "$" + strconv.Itoa(offset+i+1)
+		default:
+			p[i] = "?"
+		}
+	}
+	return strings.Join(p, ", ")
+}
+
+func anys(s []string) []any {
+	result := make([]any, len(s))
+	for i, v := range s {
+		result[i] = v
+	}
+	return result
+}
@@ -0,0 +1,169 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/inngest/inngestgo"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a minimal database/sql driver which records statements.
+type recorder struct {
+	l     sync.Mutex
+	execs []stmt
+	rows  [][]driver.Value
+}
+
+type stmt struct {
+	query string
+	args  []driver.Value
+}
+
+var (
+	recorders   = map[string]*recorder{}
+	recordersMu sync.Mutex
+)
+
+func init() {
+	sql.Register("outbox-recorder", recorderDriver{})
+}
+
+type recorderDriver struct{}
+
+func (recorderDriver) Open(name string) (driver.Conn, error) {
+	recordersMu.Lock()
+	defer recordersMu.Unlock()
+	return &recorderConn{r: recorders[name]}, nil
+}
+
+type recorderConn struct{ r *recorder }
+
+func (c *recorderConn) Prepare(query string) (driver.Stmt, error) {
+	return &recorderStmt{r: c.r, query: query}, nil
+}
+func (c *recorderConn) Close() error              { return nil }
+func (c *recorderConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *recorderConn) Commit() error             { return nil }
+func (c *recorderConn) Rollback() error           { return nil }
+
+type recorderStmt struct {
+	r     *recorder
+	query string
+}
+
+func (s *recorderStmt) Close() error  { return nil }
+func (s *recorderStmt) NumInput() int { return -1 }
+
+func (s *recorderStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.r.l.Lock()
+	defer s.r.l.Unlock()
+	s.r.execs = append(s.r.execs, stmt{query: s.query, args: args})
+	return driver.RowsAffected(1), nil
+}
+
+func (s *recorderStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.r.l.Lock()
+	defer s.r.l.Unlock()
+	s.r.execs = append(s.r.execs, stmt{query: s.query, args: args})
+	return &recorderRows{rows: s.r.rows}, nil
+}
+
+type recorderRows struct {
+	rows [][]driver.Value
+}
+
+func (r *recorderRows) Columns() []string {
+	return []string{"id", "event", "attempts", "created_at"}
+}
+func (r *recorderRows) Close() error { return nil }
+
+func (r *recorderRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func openRecorder(t *testing.T) (*sql.DB, *recorder) {
+	rec := &recorder{}
+	name := t.Name()
+	recordersMu.Lock()
+	recorders[name] = rec
+	recordersMu.Unlock()
+
+	db, err := sql.Open("outbox-recorder", name)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return db, rec
+}
+
+func TestSQLStoreEnqueue(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	db, rec := openRecorder(t)
+
+	store := NewSQLStore(db, SQLStoreOpts{Placeholder: PlaceholderDollar})
+
+	tx, err := db.BeginTx(ctx, nil)
+	r.NoError(err)
+	ids, err := store.Enqueue(ctx, tx, inngestgo.Event{Name: "user/created"})
+	r.NoError(err)
+	r.NoError(tx.Commit())
+
+	r.Len(ids, 1)
+	r.Len(rec.execs, 1)
+	r.Equal(
+		"INSERT INTO inngest_outbox (id, event, attempts, next_attempt_at, created_at) VALUES ($1, $2, $3, $4, $5)",
+		rec.execs[0].query,
+	)
+	r.Equal(ids[0], rec.execs[0].args[0])
+	r.Contains(rec.execs[0].args[1], fmt.Sprintf(`"id":%q`, ids[0]))
+}
+
+func TestSQLStore(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	db, rec := openRecorder(t)
+
+	store := NewSQLStore(db, SQLStoreOpts{Table: "events_outbox"})
+	now := time.UnixMilli(1_700_000_000_000)
+
+	rec.rows = [][]driver.Value{
+		{"a", `{"id":"a","name":"test/event"}`, int64(2), now.UnixMilli()},
+	}
+	pending, err := store.Pending(ctx, now, 10)
+	r.NoError(err)
+	r.Equal([]Record{{
+		ID:        "a",
+		Event:     []byte(`{"id":"a","name":"test/event"}`),
+		Attempts:  2,
+		CreatedAt: now,
+	}}, pending)
+
+	r.NoError(store.Retry(ctx, []string{"a", "b"}, now, "boom"))
+	r.NoError(store.Delete(ctx, []string{"a"}))
+
+	r.Equal([]stmt{
+		{
+			query: "SELECT id, event, attempts, created_at FROM events_outbox WHERE next_attempt_at <= ? ORDER BY created_at, id LIMIT 10",
+			args:  []driver.Value{now.UnixMilli()},
+		},
+		{
+			query: "UPDATE events_outbox SET attempts = attempts + 1, next_attempt_at = ?, last_error = ? WHERE id IN (?, ?)",
+			args:  []driver.Value{now.UnixMilli(), "boom", "a", "b"},
+		},
+		{
+			query: "DELETE FROM events_outbox WHERE id IN (?)",
+			args:  []driver.Value{"a"},
+		},
+	}, rec.execs)
+}
@@ -0,0 +1,268 @@
+// Package jsonschema generates JSON Schema documents from Go types, following the
+// same field naming rules as encoding/json.
+package jsonschema
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema dialect generated by this package.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema document.  Only the subset of keywords required to
+// describe Go types is supported.
+type Schema struct {
+	Schema string             `json:"$schema,omitempty"`
+	Ref    string             `json:"$ref,omitempty"`
+	Defs   map[string]*Schema `json:"$defs,omitempty"`
+
+	Type            Types  `json:"type,omitempty"`
+	Format          string `json:"format,omitempty"`
+	ContentEncoding string `json:"contentEncoding,omitempty"`
+
+	// Properties, Required and AdditionalProperties describe objects.
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+
+	// Items describes the elements of arrays.
+	Items *Schema `json:"items,omitempty"`
+
+	// AnyOf allows values matching any of the given schemas.  This is used to
+	// allow null in place of a $ref.
+	AnyOf []*Schema `json:"anyOf,omitempty"`
+}
+
+// Types lists the JSON types allowed by a schema.  A single type is encoded as a
+// string, and multiple types as an array.
+type Types []string
+
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *Types) UnmarshalJSON(byt []byte) error {
+	var single string
+	if err := json.Unmarshal(byt, &single); err == nil {
+		*t = Types{single}
+		return nil
+	}
+	return json.Unmarshal(byt, (*[]string)(t))
+}
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// For returns the JSON Schema for T.
+func For[T any]() *Schema {
+	return Reflect(reflect.TypeFor[T]())
+}
+
+// Reflect returns the JSON Schema for the given type.  Recursive types are
+// described using $defs.  A nil type, such as the type of an untyped nil, returns
+// an empty schema which allows any value.
+func Reflect(t reflect.Type) *Schema {
+	r := &reflector{
+		visiting:  map[reflect.Type]bool{},
+		recursive: map[reflect.Type]bool{},
+		defs:      map[string]*Schema{},
+	}
+
+	s := &Schema{}
+	if t != nil {
+		s = r.reflect(t)
+	}
+	if len(r.defs) > 0 {
+		s.Defs = r.defs
+	}
+	s.Schema = Draft
+	return s
+}
+
+type reflector struct {
+	// visiting records the struct types currently being generated, allowing us to
+	// detect recursive types.
+	visiting map[reflect.Type]bool
+	// recursive records the struct types which reference themselves, which are
+	// stored within defs.
+	recursive map[reflect.Type]bool
+	defs      map[string]*Schema
+}
+
+func (r *reflector) reflect(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		// encoding/json encodes nil pointers as null.
+		return nullable(r.reflect(t.Elem()))
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: Types{"string"}, Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		// Custom marshalling may produce anything.
+		return &Schema{}
+	}
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return &Schema{Type: Types{"string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: Types{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{"number"}}
+	case reflect.String:
+		return &Schema{Type: Types{"string"}}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json encodes []byte as a base64 string.
+			return &Schema{Type: Types{"string", "null"}, ContentEncoding: "base64"}
+		}
+		// Nil slices and maps are encoded as null.
+		return &Schema{Type: Types{"array", "null"}, Items: r.reflect(t.Elem())}
+	case reflect.Array:
+		return &Schema{Type: Types{"array"}, Items: r.reflect(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: Types{"object", "null"}, AdditionalProperties: r.reflect(t.Elem())}
+	case reflect.Struct:
+		return r.reflectStruct(t)
+	}
+
+	// Interfaces and any other kinds may contain any value.
+	return &Schema{}
+}
+
+// nullable returns s, also allowing null.
+func nullable(s *Schema) *Schema {
+	switch {
+	case s.Ref != "":
+		return &Schema{AnyOf: []*Schema{s, {Type: Types{"null"}}}}
+	case len(s.Type) == 0:
+		// The schema already allows any value.
+		return s
+	case slices.Contains(s.Type, "null"):
+		return s
+	}
+	s.Type = append(s.Type, "null")
+	return s
+}
+
+func (r *reflector) reflectStruct(t reflect.Type) *Schema {
+	name := defName(t)
+	if r.visiting[t] {
+		r.recursive[t] = true
+		return &Schema{Ref: "#/$defs/" + name}
+	}
+
+	r.visiting[t] = true
+	s := &Schema{Type: Types{"object"}, Properties: map[string]*Schema{}}
+	r.addFields(s, t, map[reflect.Type]bool{t: true}, false)
+	delete(r.visiting, t)
+
+	if len(s.Properties) == 0 {
+		s.Properties = nil
+	}
+
+	if r.recursive[t] {
+		r.defs[name] = s
+		return &Schema{Ref: "#/$defs/" + name}
+	}
+	return s
+}
+
+// addFields adds all fields within the struct t to s, inlining embedded structs as
+// encoding/json does.  Fields at shallower depths take precedence, so embedded
+// structs are added after all direct fields.  inlined records the struct types
+// already inlined into s:  as with encoding/json, a struct which embeds itself is
+// only inlined once.  Fields of embedded pointers are never required, as they're
+// omitted when the pointer is nil.
+func (r *reflector) addFields(s *Schema, t reflect.Type, inlined map[reflect.Type]bool, optional bool) {
+	type embed struct {
+		t        reflect.Type
+		optional bool
+	}
+	embedded := []embed{}
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			isPtr := ft.Kind() == reflect.Pointer
+			if isPtr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if !inlined[ft] {
+					inlined[ft] = true
+					embedded = append(embedded, embed{t: ft, optional: optional || isPtr})
+				}
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		if _, ok := s.Properties[name]; ok {
+			continue
+		}
+
+		prop := r.reflect(sf.Type)
+		if hasOpt(opts, "string") {
+			prop = &Schema{Type: Types{"string"}}
+		}
+		s.Properties[name] = prop
+
+		if !optional && !hasOpt(opts, "omitempty") && !hasOpt(opts, "omitzero") && sf.Type.Kind() != reflect.Pointer {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	for _, e := range embedded {
+		r.addFields(s, e.t, inlined, e.optional)
+	}
+}
+
+func hasOpt(opts, opt string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+func defName(t reflect.Type) string {
+	name := t.Name()
+	if name == "" {
+		name = t.String()
+	}
+	return strings.NewReplacer("[", "_", "]", "", "/", ".", " ", "", "*", "").Replace(name)
+}
@@ -0,0 +1,160 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type base struct {
+	ID string `json:"id"`
+}
+
+type node struct {
+	Value    int     `json:"value"`
+	Children []*node `json:"children,omitempty"`
+}
+
+type payload struct {
+	base
+
+	Name      string            `json:"name"`
+	Count     int               `json:"count,omitempty"`
+	Ratio     float64           `json:"ratio"`
+	Enabled   bool              `json:"enabled"`
+	Tags      []string          `json:"tags"`
+	Labels    map[string]string `json:"labels"`
+	Raw       json.RawMessage   `json:"raw"`
+	Bytes     []byte            `json:"bytes"`
+	At        time.Time         `json:"at"`
+	Optional  *string           `json:"optional"`
+	Quoted    int64             `json:"quoted,string"`
+	Any       any               `json:"any"`
+	Untagged  string
+	Ignored   string `json:"-"`
+	unexposed string
+}
+
+func TestFor(t *testing.T) {
+	r := require.New(t)
+
+	byt, err := json.Marshal(For[payload]())
+	r.NoError(err)
+
+	r.JSONEq(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"id": {"type": "string"},
+			"name": {"type": "string"},
+			"count": {"type": "integer"},
+			"ratio": {"type": "number"},
+			"enabled": {"type": "boolean"},
+			"tags": {"type": ["array", "null"], "items": {"type": "string"}},
+			"labels": {"type": ["object", "null"], "additionalProperties": {"type": "string"}},
+			"raw": {},
+			"bytes": {"type": ["string", "null"], "contentEncoding": "base64"},
+			"at": {"type": "string", "format": "date-time"},
+			"optional": {"type": ["string", "null"]},
+			"quoted": {"type": "string"},
+			"any": {},
+			"Untagged": {"type": "string"}
+		},
+		"required": ["name", "ratio", "enabled", "tags", "labels", "raw", "bytes", "at", "quoted", "any", "Untagged", "id"]
+	}`, string(byt))
+}
+
+func TestForRecursive(t *testing.T) {
+	r := require.New(t)
+
+	byt, err := json.Marshal(For[node]())
+	r.NoError(err)
+
+	r.JSONEq(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$ref": "#/$defs/node",
+		"$defs": {
+			"node": {
+				"type": "object",
+				"properties": {
+					"value": {"type": "integer"},
+					"children": {
+						"type": ["array", "null"],
+						"items": {"anyOf": [{"$ref": "#/$defs/node"}, {"type": "null"}]}
+					}
+				},
+				"required": ["value"]
+			}
+		}
+	}`, string(byt))
+}
+
+type selfEmbedding struct {
+	*selfEmbedding
+	*extra
+
+	Name string `json:"name"`
+}
+
+type extra struct {
+	*selfEmbedding
+
+	Note string `json:"note"`
+}
+
+func TestForEmbeddedCycle(t *testing.T) {
+	r := require.New(t)
+
+	byt, err := json.Marshal(For[selfEmbedding]())
+	r.NoError(err)
+
+	r.JSONEq(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"note": {"type": "string"}
+		},
+		"required": ["name"]
+	}`, string(byt))
+}
+
+func TestForValidatesNil(t *testing.T) {
+	// Nil slices, maps and pointers are encoded as null, so the schema must allow
+	// null for each of them.
+	byt, err := json.Marshal(payload{})
+	require.NoError(t, err)
+
+	var encoded map[string]any
+	require.NoError(t, json.Unmarshal(byt, &encoded))
+
+	s := For[payload]()
+	for _, name := range []string{"tags", "labels", "bytes", "optional"} {
+		require.Nil(t, encoded[name], name)
+		require.Contains(t, s.Properties[name].Type, "null", name)
+	}
+}
+
+func TestTypesJSON(t *testing.T) {
+	r := require.New(t)
+
+	for encoded, types := range map[string]Types{
+		`"string"`:         {"string"},
+		`["array","null"]`: {"array", "null"},
+	} {
+		byt, err := json.Marshal(types)
+		r.NoError(err)
+		r.Equal(encoded, string(byt))
+
+		var decoded Types
+		r.NoError(json.Unmarshal(byt, &decoded))
+		r.Equal(types, decoded)
+	}
+}
+
+func TestReflectNil(t *testing.T) {
+	s := Reflect(nil)
+	require.Equal(t, &Schema{Schema: Draft}, s)
+}
@@ -0,0 +1,95 @@
+// Package replay protects against signed requests being replayed.
+//
+// Request signatures are valid for a short window after they're created, so a
+// captured request could be resent within that window.  A Store records the
+// signatures of accepted requests until they expire, allowing duplicates to be
+// rejected.
+package replay
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultLRUSize is the default number of signatures held by an LRU.
+const DefaultLRUSize = 10_000
+
+// Store records request signatures which have been seen.  Stores must be safe for
+// concurrent use.  Apps running multiple replicas should use a shared store, eg.
+// backed by Redis using SET NX, so that requests can't be replayed against other
+// replicas.
+type Store interface {
+	// Add records the signature until it expires, returning false if the
+	// signature has already been recorded and hasn't yet expired.
+	Add(ctx context.Context, sig string, expires time.Time) (bool, error)
+}
+
+// LRU is an in-memory Store which holds a limited number of signatures, evicting
+// the least recently added signatures once full.
+type LRU struct {
+	size int
+	now  func() time.Time
+
+	l     sync.Mutex
+	order *list.List
+	items map[string]*list.Element
+}
+
+type entry struct {
+	sig     string
+	expires time.Time
+}
+
+// NewLRU returns an in-memory Store holding up to size signatures, defaulting to
+// DefaultLRUSize.  The size should exceed the number of requests expected within
+// the signature validity window, as evicted signatures can be replayed.
+func NewLRU(size int) *LRU {
+	if size <= 0 {
+		size = DefaultLRUSize
+	}
+	return &LRU{
+		size:  size,
+		now:   time.Now,
+		order: list.New(),
+		items: map[string]*list.Element{},
+	}
+}
+
+// Add records the signature, returning false if it's already been recorded.
+func (c *LRU) Add(ctx context.Context, sig string, expires time.Time) (bool, error) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	now := c.now()
+	if el, ok := c.items[sig]; ok {
+		if el.Value.(*entry).expires.After(now) {
+			return false, nil
+		}
+		c.remove(el)
+	}
+
+	// Drop expired signatures, then the oldest signatures if still full.
+	for el := c.order.Back(); el != nil && !el.Value.(*entry).expires.After(now); el = c.order.Back() {
+		c.remove(el)
+	}
+	for c.order.Len() >= c.size {
+		c.remove(c.order.Back())
+	}
+
+	c.items[sig] = c.order.PushFront(&entry{sig: sig, expires: expires})
+	return true, nil
+}
+
+// Len returns the number of signatures held.
+func (c *LRU) Len() int {
+	c.l.Lock()
+	defer c.l.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRU) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*entry).sig)
+}
@@ -0,0 +1,63 @@
+package replay
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRU(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	c := NewLRU(2)
+	c.now = func() time.Time { return now }
+
+	ok, err := c.Add(ctx, "a", now.Add(time.Minute))
+	r.NoError(err)
+	r.True(ok)
+
+	// Duplicate signatures are rejected until they expire.
+	ok, _ = c.Add(ctx, "a", now.Add(time.Minute))
+	r.False(ok)
+
+	now = now.Add(time.Minute)
+	ok, _ = c.Add(ctx, "a", now.Add(time.Minute))
+	r.True(ok)
+	r.Equal(1, c.Len())
+
+	// The oldest signatures are evicted once full.
+	ok, _ = c.Add(ctx, "b", now.Add(time.Minute))
+	r.True(ok)
+	ok, _ = c.Add(ctx, "c", now.Add(time.Minute))
+	r.True(ok)
+	r.Equal(2, c.Len())
+
+	ok, _ = c.Add(ctx, "b", now.Add(time.Minute))
+	r.False(ok)
+	ok, _ = c.Add(ctx, "a", now.Add(time.Minute))
+	r.True(ok)
+}
+
+func TestLRUExpiry(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	c := NewLRU(0)
+	c.now = func() time.Time { return now }
+
+	for _, sig := range []string{"a", "b", "c"} {
+		ok, _ := c.Add(ctx, sig, now.Add(time.Minute))
+		r.True(ok)
+	}
+
+	// Expired signatures are dropped when adding new signatures.
+	now = now.Add(2 * time.Minute)
+	ok, _ := c.Add(ctx, "d", now.Add(time.Minute))
+	r.True(ok)
+	r.Equal(1, c.Len())
+}
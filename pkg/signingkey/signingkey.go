@@ -0,0 +1,217 @@
+// Package signingkey supplies signing keys to the SDK, allowing keys to be rotated
+// without restarting.
+//
+// Every path which uses signing keys — serving and validating requests, syncing,
+// Connect, API checkpointing and realtime — consults a Provider whenever a key is
+// needed, so newly rotated keys are used as soon as the provider returns them.
+package signingkey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Keys is a signing key alongside an optional fallback signing key, used while
+// rotating keys.
+type Keys struct {
+	// Current is the current signing key.
+	Current string
+	// Fallback is the optional fallback signing key.
+	Fallback string
+}
+
+// Provider supplies signing keys.  Providers are called for every request which
+// uses signing keys and must be safe for concurrent use; providers which load
+// keys from remote sources should cache them, eg. using NewCache.
+type Provider interface {
+	SigningKeys(ctx context.Context) (Keys, error)
+}
+
+// ProviderFunc adapts a function to a Provider.
+type ProviderFunc func(ctx context.Context) (Keys, error)
+
+func (f ProviderFunc) SigningKeys(ctx context.Context) (Keys, error) {
+	return f(ctx)
+}
+
+// Static returns a Provider which always returns the given keys.
+func Static(current, fallback string) Provider {
+	return ProviderFunc(func(ctx context.Context) (Keys, error) {
+		return Keys{Current: current, Fallback: fallback}, nil
+	})
+}
+
+// Env returns a Provider which reads keys from the INNGEST_SIGNING_KEY and
+// INNGEST_SIGNING_KEY_FALLBACK environment variables.
+func Env() Provider {
+	return ProviderFunc(func(ctx context.Context) (Keys, error) {
+		return Keys{
+			Current:  os.Getenv("INNGEST_SIGNING_KEY"),
+			Fallback: os.Getenv("INNGEST_SIGNING_KEY_FALLBACK"),
+		}, nil
+	})
+}
+
+// Cache is a Provider which caches keys returned by a load function, refreshing
+// them once they're older than the cache's TTL.  Keys are loaded by a single
+// caller at a time, outside of the cache's lock:  while a refresh is in flight,
+// other callers are given the previously loaded keys.  If refreshing fails, the
+// previously loaded keys continue to be used, and refreshes are retried with an
+// exponential backoff so that an unavailable provider isn't called on every
+// request.
+type Cache struct {
+	load ProviderFunc
+	ttl  time.Duration
+	now  func() time.Time
+
+	l       sync.Mutex
+	keys    Keys
+	hasKeys bool
+	expires time.Time
+	// loading is closed once the in-flight load completes, or nil if no load is
+	// in flight.
+	loading chan struct{}
+	// err is the error from the last failed load.  failures is the number of
+	// consecutive failed loads, and no loads are attempted until retryAt.
+	err      error
+	failures int
+	retryAt  time.Time
+}
+
+const (
+	minRetryDelay = time.Second
+	maxRetryDelay = time.Minute
+)
+
+// NewCache returns a Cache which loads keys using the given function, eg. from a
+// secret manager, refreshing them every ttl.
+func NewCache(load func(ctx context.Context) (Keys, error), ttl time.Duration) *Cache {
+	return &Cache{load: load, ttl: ttl, now: time.Now}
+}
+
+// NewFile returns a Cache which reads keys from the given file, refreshing them
+// every ttl.  The file contains the current signing key on its first line and an
+// optional fallback key on its second line, as typically mounted from a secret
+// manager.
+func NewFile(path string, ttl time.Duration) *Cache {
+	return NewCache(func(ctx context.Context) (Keys, error) {
+		byt, err := os.ReadFile(path)
+		if err != nil {
+			return Keys{}, fmt.Errorf("error reading signing keys: %w", err)
+		}
+
+		lines := []string{}
+		for _, line := range strings.Split(string(byt), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		if len(lines) == 0 {
+			return Keys{}, fmt.Errorf("no signing key found in %s", path)
+		}
+
+		keys := Keys{Current: lines[0]}
+		if len(lines) > 1 {
+			keys.Fallback = lines[1]
+		}
+		return keys, nil
+	}, ttl)
+}
+
+// SigningKeys returns the cached keys, refreshing them if they have expired.
+func (c *Cache) SigningKeys(ctx context.Context) (Keys, error) {
+	c.l.Lock()
+	now := c.now()
+	if c.hasKeys && now.Before(c.expires) {
+		defer c.l.Unlock()
+		return c.keys, nil
+	}
+	if now.Before(c.retryAt) {
+		// Back off after a failed load.
+		defer c.l.Unlock()
+		return c.cached()
+	}
+	if c.loading != nil {
+		wait := c.loading
+		if c.hasKeys {
+			// Use the stale keys rather than waiting for the refresh.
+			defer c.l.Unlock()
+			return c.keys, nil
+		}
+		c.l.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return Keys{}, ctx.Err()
+		}
+		c.l.Lock()
+		defer c.l.Unlock()
+		return c.cached()
+	}
+
+	done := make(chan struct{})
+	c.loading = done
+	c.l.Unlock()
+
+	keys, err := c.load(ctx)
+
+	c.l.Lock()
+	defer c.l.Unlock()
+	close(done)
+	c.loading = nil
+
+	if err != nil {
+		if ctx.Err() == nil {
+			// Only back off if the provider failed, rather than the caller
+			// giving up.
+			c.err = err
+			c.failures++
+			c.retryAt = c.now().Add(retryDelay(c.failures))
+		}
+		if !c.hasKeys {
+			return Keys{}, err
+		}
+		// Use the stale keys until they can be refreshed.
+		return c.keys, nil
+	}
+
+	c.keys = keys
+	c.hasKeys = true
+	c.expires = c.now().Add(c.ttl)
+	c.err = nil
+	c.failures = 0
+	c.retryAt = time.Time{}
+	return keys, nil
+}
+
+// cached returns the last loaded keys, or the last load error if keys have never
+// been loaded.  This must be called with the lock held.
+func (c *Cache) cached() (Keys, error) {
+	if c.hasKeys {
+		return c.keys, nil
+	}
+	if c.err != nil {
+		return Keys{}, c.err
+	}
+	return Keys{}, errors.New("signing keys have not been loaded")
+}
+
+// Invalidate forces the keys to be reloaded on next use, without backing off
+// from any previous failure.
+func (c *Cache) Invalidate() {
+	c.l.Lock()
+	defer c.l.Unlock()
+	c.expires = time.Time{}
+	c.retryAt = time.Time{}
+}
+
+func retryDelay(failures int) time.Duration {
+	delay := minRetryDelay << min(failures-1, 16)
+	return min(delay, maxRetryDelay)
+}
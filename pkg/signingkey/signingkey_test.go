@@ -0,0 +1,168 @@
+package signingkey
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	var (
+		loads int
+		err   error
+		now   = time.Now()
+	)
+	c := NewCache(func(ctx context.Context) (Keys, error) {
+		loads++
+		return Keys{Current: fmt.Sprintf("key-%d", loads)}, err
+	}, time.Minute)
+	c.now = func() time.Time { return now }
+
+	keys, _ := c.SigningKeys(ctx)
+	r.Equal("key-1", keys.Current)
+
+	// Keys are cached until the TTL passes.
+	keys, _ = c.SigningKeys(ctx)
+	r.Equal("key-1", keys.Current)
+
+	now = now.Add(time.Minute)
+	keys, _ = c.SigningKeys(ctx)
+	r.Equal("key-2", keys.Current)
+
+	// Stale keys are used if refreshing fails.
+	err = fmt.Errorf("unavailable")
+	now = now.Add(time.Minute)
+	keys, rerr := c.SigningKeys(ctx)
+	r.NoError(rerr)
+	r.Equal("key-2", keys.Current)
+
+	err = nil
+	c.Invalidate()
+	keys, _ = c.SigningKeys(ctx)
+	r.Equal("key-4", keys.Current)
+}
+
+func TestCacheError(t *testing.T) {
+	c := NewCache(func(ctx context.Context) (Keys, error) {
+		return Keys{}, fmt.Errorf("unavailable")
+	}, time.Minute)
+
+	_, err := c.SigningKeys(context.Background())
+	require.EqualError(t, err, "unavailable")
+}
+
+func TestCacheBackoff(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	var loads int
+	now := time.Now()
+	c := NewCache(func(ctx context.Context) (Keys, error) {
+		loads++
+		return Keys{}, fmt.Errorf("unavailable")
+	}, time.Minute)
+	c.now = func() time.Time { return now }
+
+	_, err := c.SigningKeys(ctx)
+	r.EqualError(err, "unavailable")
+
+	// Failed loads aren't retried until the backoff passes.
+	_, err = c.SigningKeys(ctx)
+	r.EqualError(err, "unavailable")
+	r.Equal(1, loads)
+
+	now = now.Add(minRetryDelay)
+	_, _ = c.SigningKeys(ctx)
+	r.Equal(2, loads)
+
+	// The backoff doubles with each failure.
+	now = now.Add(minRetryDelay)
+	_, _ = c.SigningKeys(ctx)
+	r.Equal(2, loads)
+	now = now.Add(minRetryDelay)
+	_, _ = c.SigningKeys(ctx)
+	r.Equal(3, loads)
+}
+
+func TestCacheLoadsOutsideLock(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	c := NewCache(func(ctx context.Context) (Keys, error) {
+		n := loads.Add(1)
+		if n > 1 {
+			<-release
+		}
+		return Keys{Current: fmt.Sprintf("key-%d", n)}, nil
+	}, 0)
+
+	keys, err := c.SigningKeys(ctx)
+	r.NoError(err)
+	r.Equal("key-1", keys.Current)
+
+	// Start a slow refresh.
+	refreshed := make(chan Keys)
+	go func() {
+		keys, _ := c.SigningKeys(ctx)
+		refreshed <- keys
+	}()
+	r.Eventually(func() bool { return loads.Load() == 2 }, time.Second, time.Millisecond)
+
+	// Other callers get the stale keys without waiting or loading again.
+	for range 5 {
+		keys, err = c.SigningKeys(ctx)
+		r.NoError(err)
+		r.Equal("key-1", keys.Current)
+	}
+	r.EqualValues(2, loads.Load())
+
+	close(release)
+	r.Equal("key-2", (<-refreshed).Current)
+}
+
+func TestCacheCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewCache(func(ctx context.Context) (Keys, error) {
+		<-ctx.Done()
+		return Keys{}, ctx.Err()
+	}, time.Minute)
+
+	cancel()
+	_, err := c.SigningKeys(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	// Cancellation isn't a provider failure, so doesn't back off.
+	require.True(t, c.retryAt.IsZero())
+}
+
+func TestFile(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "keys")
+
+	r.NoError(os.WriteFile(path, []byte("signkey-test-a\n"), 0o600))
+	c := NewFile(path, 0)
+
+	keys, err := c.SigningKeys(ctx)
+	r.NoError(err)
+	r.Equal(Keys{Current: "signkey-test-a"}, keys)
+
+	// Rotate the key, keeping the previous key as the fallback.
+	r.NoError(os.WriteFile(path, []byte("signkey-test-b\nsignkey-test-a\n"), 0o600))
+	keys, err = c.SigningKeys(ctx)
+	r.NoError(err)
+	r.Equal(Keys{Current: "signkey-test-b", Fallback: "signkey-test-a"}, keys)
+
+	_, err = NewFile(filepath.Join(t.TempDir(), "missing"), 0).SigningKeys(ctx)
+	r.Error(err)
+}
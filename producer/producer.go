@@ -0,0 +1,472 @@
+// Package producer sends events asynchronously in batches.
+//
+// Client.Send makes a blocking HTTP request for every call.  A Producer instead
+// buffers events and sends them in batches via Client.SendMany once a batch is
+// full or a flush interval passes, trading a small delay for far fewer requests:
+//
+//	p, _ := producer.New(producer.Opts{Client: client})
+//	defer p.Close(ctx)
+//
+//	fut, err := p.Send(ctx, inngestgo.Event{Name: "api/request.received"})
+//	// Optionally, wait for the event to be sent.
+//	id, err := fut.Wait(ctx)
+package producer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/inngest/inngestgo"
+)
+
+const (
+	defaultMaxBatchSize      = 100
+	defaultMaxBatchBytes     = 256 * 1024
+	defaultFlushInterval     = 100 * time.Millisecond
+	defaultMaxBufferedEvents = 10_000
+	defaultMaxBufferedBytes  = 16 * 1024 * 1024
+)
+
+var (
+	// ErrBufferFull is returned by Send when the buffer is full and the
+	// overflow policy is OverflowDrop.
+	ErrBufferFull = fmt.Errorf("producer buffer is full")
+	// ErrClosed is returned by Send once the producer is closed, and is the error
+	// of any events which are unsent when Close returns.
+	ErrClosed = fmt.Errorf("producer is closed")
+	// ErrEventTooLarge is returned by Send when an event exceeds MaxBatchBytes.
+	ErrEventTooLarge = fmt.Errorf("event exceeds max batch bytes")
+)
+
+// Sender sends events to Inngest.  This is implemented by inngestgo.Client.
+type Sender interface {
+	SendMany(ctx context.Context, e []any) ([]string, error)
+}
+
+// OverflowPolicy determines what happens when sending an event to a full buffer.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks Send until there's space in the buffer or the context
+	// is done.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDrop drops the event, returning ErrBufferFull from Send.
+	OverflowDrop
+)
+
+// Opts configures a Producer.
+type Opts struct {
+	// Client sends batches of events.  This is typically an inngestgo.Client.
+	Client Sender
+
+	// MaxBatchSize is the maximum number of events sent in a single request,
+	// defaulting to 100.  A batch is sent as soon as it is full.
+	MaxBatchSize int
+	// MaxBatchBytes is the maximum JSON-encoded size of a single request,
+	// defaulting to 256KiB.  A batch is sent as soon as it is full.
+	MaxBatchBytes int
+	// FlushInterval is the maximum time an event is buffered before being sent,
+	// defaulting to 100ms.
+	FlushInterval time.Duration
+	// Concurrency is the maximum number of batches sent concurrently, defaulting
+	// to 1.
+	Concurrency int
+
+	// MaxBufferedEvents is the maximum number of events buffered or being sent,
+	// defaulting to 10,000.
+	MaxBufferedEvents int
+	// MaxBufferedBytes is the maximum JSON-encoded size of events buffered or
+	// being sent, defaulting to 16MiB.
+	MaxBufferedBytes int
+	// Overflow determines what happens when sending an event to a full buffer,
+	// defaulting to OverflowBlock.
+	Overflow OverflowPolicy
+
+	// OnResult, if set, is called with the result of every event once sent.  It
+	// is called from the producer's goroutines and must not block.
+	OnResult func(Result)
+}
+
+// Result is the outcome of sending a single event.
+type Result struct {
+	// Event is the event passed to Send.
+	Event any
+	// ID is the event's ID, if sent successfully.
+	ID string
+	// Err is the error sending the event.
+	Err error
+}
+
+// Future is the pending result of an event passed to Send.
+type Future struct {
+	done   chan struct{}
+	result Result
+}
+
+// Done returns a channel which is closed once the event has been sent or has
+// failed.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait waits for the event to be sent, returning its ID.
+func (f *Future) Wait(ctx context.Context) (string, error) {
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case <-f.done:
+		return f.result.ID, f.result.Err
+	}
+}
+
+type item struct {
+	evt  any
+	byt  json.RawMessage
+	fut  *Future
+	size int
+}
+
+// Producer buffers events and sends them in batches.  A Producer is safe for
+// concurrent use.
+type Producer struct {
+	opts Opts
+
+	// ctx is cancelled when Close gives up waiting for in-flight batches.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	l        sync.Mutex
+	buf      []*item
+	bufBytes int
+	// used is the number and size of events which are buffered or in-flight,
+	// which is bounded by MaxBufferedEvents and MaxBufferedBytes.
+	usedEvents int
+	usedBytes  int
+	// space is closed and replaced whenever buffer space is freed.
+	space  chan struct{}
+	closed bool
+
+	kick     chan struct{}
+	stop     chan struct{}
+	loopDone chan struct{}
+	sem      chan struct{}
+	inflight sync.WaitGroup
+}
+
+// New returns a Producer which starts sending events in the background.  Close
+// must be called to send any buffered events and stop the producer.
+func New(opts Opts) (*Producer, error) {
+	if opts.Client == nil {
+		return nil, fmt.Errorf("a client is required")
+	}
+	if opts.MaxBatchSize <= 0 {
+		opts.MaxBatchSize = defaultMaxBatchSize
+	}
+	if opts.MaxBatchBytes <= 0 {
+		opts.MaxBatchBytes = defaultMaxBatchBytes
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultFlushInterval
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if opts.MaxBufferedEvents <= 0 {
+		opts.MaxBufferedEvents = defaultMaxBufferedEvents
+	}
+	if opts.MaxBufferedBytes <= 0 {
+		opts.MaxBufferedBytes = defaultMaxBufferedBytes
+	}
+	if opts.MaxBufferedBytes < opts.MaxBatchBytes {
+		return nil, fmt.Errorf("max buffered bytes must be at least max batch bytes")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Producer{
+		opts:     opts,
+		ctx:      ctx,
+		cancel:   cancel,
+		space:    make(chan struct{}),
+		kick:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		loopDone: make(chan struct{}),
+		sem:      make(chan struct{}, opts.Concurrency),
+	}
+	go p.loop()
+	return p, nil
+}
+
+type validatable interface {
+	Validate() error
+}
+
+// Send buffers the given event, returning a Future for its result.  If the
+// buffer is full Send blocks until there's space or the context is done, or
+// returns ErrBufferFull if the overflow policy is OverflowDrop.
+func (p *Producer) Send(ctx context.Context, evt any) (*Future, error) {
+	if v, ok := evt.(validatable); ok {
+		if err := v.Validate(); err != nil {
+			return nil, fmt.Errorf("error validating event: %w", err)
+		}
+	}
+	byt, err := json.Marshal(evt)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling event to json: %w", err)
+	}
+	// Account for the brackets and separating comma within the batch.
+	size := len(byt) + 1
+	if size+1 > p.opts.MaxBatchBytes {
+		return nil, ErrEventTooLarge
+	}
+
+	it := &item{
+		evt:  evt,
+		byt:  byt,
+		fut:  &Future{done: make(chan struct{})},
+		size: size,
+	}
+
+	p.l.Lock()
+	for {
+		if p.closed {
+			p.l.Unlock()
+			return nil, ErrClosed
+		}
+		if p.usedEvents < p.opts.MaxBufferedEvents && p.usedBytes+size <= p.opts.MaxBufferedBytes {
+			break
+		}
+		if p.opts.Overflow == OverflowDrop {
+			p.l.Unlock()
+			return nil, ErrBufferFull
+		}
+
+		space := p.space
+		p.l.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-space:
+		}
+		p.l.Lock()
+	}
+
+	p.usedEvents++
+	p.usedBytes += size
+	p.buf = append(p.buf, it)
+	p.bufBytes += size
+	full := len(p.buf) >= p.opts.MaxBatchSize || p.bufBytes+1 >= p.opts.MaxBatchBytes
+	p.l.Unlock()
+
+	if full {
+		select {
+		case p.kick <- struct{}{}:
+		default:
+		}
+	}
+	return it.fut, nil
+}
+
+// Flush sends all buffered events and waits for every in-flight batch to
+// complete, or for the context to be done.
+func (p *Producer) Flush(ctx context.Context) error {
+	for {
+		batch := p.next(true)
+		if len(batch) == 0 {
+			break
+		}
+		if err := p.dispatch(ctx, batch); err != nil {
+			p.requeue(batch)
+			return err
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-done:
+		return nil
+	}
+}
+
+// Close stops accepting events and sends all buffered events, waiting until
+// they're sent or the context is done.  If the context is done first, in-flight
+// requests are cancelled and any unsent events fail with ErrClosed.
+func (p *Producer) Close(ctx context.Context) error {
+	p.l.Lock()
+	if p.closed {
+		p.l.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.space)
+	p.l.Unlock()
+
+	close(p.stop)
+	<-p.loopDone
+
+	err := p.Flush(ctx)
+	if err != nil {
+		p.cancel()
+		p.inflight.Wait()
+		for batch := p.next(true); len(batch) > 0; batch = p.next(true) {
+			for _, it := range batch {
+				p.complete(it, "", ErrClosed)
+			}
+		}
+	}
+	p.cancel()
+	return err
+}
+
+func (p *Producer) loop() {
+	defer close(p.loopDone)
+
+	t := time.NewTicker(p.opts.FlushInterval)
+	defer t.Stop()
+
+	// Stop waiting for a free slot once the producer closes, leaving Close to
+	// flush the remaining events.
+	ctx, cancel := context.WithCancel(p.ctx)
+	defer cancel()
+	go func() {
+		<-p.stop
+		cancel()
+	}()
+
+	for {
+		all := false
+		select {
+		case <-p.stop:
+			return
+		case <-p.kick:
+		case <-t.C:
+			all = true
+		}
+
+		for {
+			batch := p.next(all)
+			if len(batch) == 0 {
+				break
+			}
+			if err := p.dispatch(ctx, batch); err != nil {
+				p.requeue(batch)
+				return
+			}
+		}
+	}
+}
+
+// next removes the next batch from the buffer.  Unless all is set, only full
+// batches are returned.
+func (p *Producer) next(all bool) []*item {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	n, bytes := 0, 1
+	for n < len(p.buf) && n < p.opts.MaxBatchSize && bytes+p.buf[n].size <= p.opts.MaxBatchBytes {
+		bytes += p.buf[n].size
+		n++
+	}
+	full := n == p.opts.MaxBatchSize || n < len(p.buf) || bytes >= p.opts.MaxBatchBytes
+	if n == 0 || (!all && !full) {
+		return nil
+	}
+
+	batch := p.buf[:n:n]
+	p.buf = p.buf[n:]
+	p.bufBytes -= bytes - 1
+	return batch
+}
+
+// requeue returns a batch to the front of the buffer.
+func (p *Producer) requeue(batch []*item) {
+	p.l.Lock()
+	defer p.l.Unlock()
+	for _, it := range batch {
+		p.bufBytes += it.size
+	}
+	p.buf = append(batch, p.buf...)
+}
+
+// dispatch sends the batch in the background once fewer than Concurrency
+// batches are in-flight.
+func (p *Producer) dispatch(ctx context.Context, batch []*item) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case p.sem <- struct{}{}:
+	}
+
+	p.inflight.Add(1)
+	go func() {
+		defer func() {
+			<-p.sem
+			p.inflight.Done()
+		}()
+		p.send(batch)
+	}()
+	return nil
+}
+
+func (p *Producer) send(batch []*item) {
+	events := make([]any, len(batch))
+	for i, it := range batch {
+		events[i] = it.byt
+	}
+
+	ids, err := p.opts.Client.SendMany(p.ctx, events)
+	errs := make([]error, len(batch))
+	var manyErr *inngestgo.SendManyError
+	if errors.As(err, &manyErr) {
+		// Only the failed chunks are unsent;  the other events were accepted and
+		// have IDs, so mustn't be retried.
+		for _, f := range manyErr.Failed {
+			for i := max(f.Start, 0); i < min(f.End, len(errs)); i++ {
+				errs[i] = f.Err
+			}
+		}
+	} else if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+	}
+
+	for i, it := range batch {
+		err := errs[i]
+		if errors.Is(err, context.Canceled) && p.ctx.Err() != nil {
+			err = ErrClosed
+		}
+		id := ""
+		if err == nil && i < len(ids) {
+			id = ids[i]
+		}
+		p.complete(it, id, err)
+	}
+}
+
+// complete resolves the event's future and frees its buffer space.
+func (p *Producer) complete(it *item, id string, err error) {
+	it.fut.result = Result{Event: it.evt, ID: id, Err: err}
+	close(it.fut.done)
+	if p.opts.OnResult != nil {
+		p.opts.OnResult(it.fut.result)
+	}
+
+	p.l.Lock()
+	p.usedEvents--
+	p.usedBytes -= it.size
+	if !p.closed {
+		close(p.space)
+		p.space = make(chan struct{})
+	}
+	p.l.Unlock()
+}
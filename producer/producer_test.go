@@ -0,0 +1,273 @@
+package producer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/inngest/inngestgo"
+	"github.com/stretchr/testify/require"
+)
+
+type sender struct {
+	l       sync.Mutex
+	err     error
+	block   chan struct{}
+	batches [][]string
+}
+
+func (s *sender) SendMany(ctx context.Context, e []any) ([]string, error) {
+	if s.block != nil {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-s.block:
+		}
+	}
+
+	s.l.Lock()
+	defer s.l.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	ids := []string{}
+	for _, evt := range e {
+		m := map[string]any{}
+		if err := json.Unmarshal(evt.(json.RawMessage), &m); err != nil {
+			return nil, err
+		}
+		ids = append(ids, fmt.Sprintf("id-%v", m["data"].(map[string]any)["i"]))
+	}
+	s.batches = append(s.batches, ids)
+	return ids, nil
+}
+
+func (s *sender) sent() [][]string {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return s.batches
+}
+
+func event(i int) inngestgo.Event {
+	return inngestgo.Event{Name: "test/event", Data: map[string]any{"i": i}}
+}
+
+func TestProducerBatchSize(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	s := &sender{}
+	p, err := New(Opts{Client: s, MaxBatchSize: 2, FlushInterval: time.Hour})
+	r.NoError(err)
+	defer func() { _ = p.Close(ctx) }()
+
+	f1, err := p.Send(ctx, event(1))
+	r.NoError(err)
+	f2, err := p.Send(ctx, event(2))
+	r.NoError(err)
+
+	id, err := f1.Wait(ctx)
+	r.NoError(err)
+	r.Equal("id-1", id)
+	id, err = f2.Wait(ctx)
+	r.NoError(err)
+	r.Equal("id-2", id)
+	r.Equal([][]string{{"id-1", "id-2"}}, s.sent())
+}
+
+func TestProducerBatchBytes(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	byt, err := json.Marshal(event(1))
+	r.NoError(err)
+
+	s := &sender{}
+	p, err := New(Opts{
+		Client: s,
+		// Fit exactly two events within each batch.
+		MaxBatchBytes: 2*(len(byt)+1) + 1,
+		FlushInterval: time.Hour,
+	})
+	r.NoError(err)
+
+	futs := []*Future{}
+	for i := 1; i <= 5; i++ {
+		f, err := p.Send(ctx, event(i))
+		r.NoError(err)
+		futs = append(futs, f)
+	}
+	_, err = futs[3].Wait(ctx)
+	r.NoError(err)
+
+	// The last event is only sent once the producer is closed.
+	select {
+	case <-futs[4].Done():
+		r.Fail("partial batch sent before flush")
+	default:
+	}
+	r.NoError(p.Close(ctx))
+
+	id, err := futs[4].Wait(ctx)
+	r.NoError(err)
+	r.Equal("id-5", id)
+	r.Equal([][]string{{"id-1", "id-2"}, {"id-3", "id-4"}, {"id-5"}}, s.sent())
+
+	_, err = p.Send(ctx, event(6))
+	r.ErrorIs(err, ErrClosed)
+}
+
+func TestProducerInterval(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	results := make(chan Result, 1)
+	p, err := New(Opts{
+		Client:        &sender{},
+		FlushInterval: 10 * time.Millisecond,
+		OnResult:      func(res Result) { results <- res },
+	})
+	r.NoError(err)
+	defer func() { _ = p.Close(ctx) }()
+
+	_, err = p.Send(ctx, event(1))
+	r.NoError(err)
+
+	select {
+	case res := <-results:
+		r.NoError(res.Err)
+		r.Equal("id-1", res.ID)
+		r.Equal(event(1), res.Event)
+	case <-time.After(time.Second):
+		r.Fail("event not sent")
+	}
+}
+
+func TestProducerErrors(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	p, err := New(Opts{Client: &sender{err: fmt.Errorf("unavailable")}})
+	r.NoError(err)
+
+	f, err := p.Send(ctx, event(1))
+	r.NoError(err)
+	r.NoError(p.Flush(ctx))
+
+	_, err = f.Wait(ctx)
+	r.EqualError(err, "unavailable")
+
+	_, err = p.Send(ctx, inngestgo.Event{})
+	r.Error(err)
+
+	r.NoError(p.Close(ctx))
+}
+
+func TestProducerPartialFailure(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	unavailable := fmt.Errorf("unavailable")
+	client := senderFunc(func(ctx context.Context, e []any) ([]string, error) {
+		// The middle chunk of the split batch fails.
+		return []string{"id-0", "", "", "id-3"}, &inngestgo.SendManyError{
+			Total:  4,
+			Failed: []inngestgo.FailedChunk{{Start: 1, End: 3, Err: unavailable}},
+		}
+	})
+	p, err := New(Opts{Client: client, MaxBatchSize: 4, FlushInterval: time.Hour})
+	r.NoError(err)
+
+	futures := make([]*Future, 4)
+	for i := range futures {
+		futures[i], err = p.Send(ctx, event(i))
+		r.NoError(err)
+	}
+	r.NoError(p.Flush(ctx))
+
+	for i, expected := range []string{"id-0", "", "", "id-3"} {
+		id, err := futures[i].Wait(ctx)
+		r.Equal(expected, id)
+		if expected == "" {
+			r.ErrorIs(err, unavailable)
+		} else {
+			r.NoError(err)
+		}
+	}
+
+	r.NoError(p.Close(ctx))
+}
+
+type senderFunc func(ctx context.Context, e []any) ([]string, error)
+
+func (f senderFunc) SendMany(ctx context.Context, e []any) ([]string, error) {
+	return f(ctx, e)
+}
+
+func TestProducerOverflow(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("drop", func(t *testing.T) {
+		r := require.New(t)
+		s := &sender{block: make(chan struct{})}
+		p, err := New(Opts{Client: s, MaxBufferedEvents: 1, Overflow: OverflowDrop})
+		r.NoError(err)
+
+		_, err = p.Send(ctx, event(1))
+		r.NoError(err)
+		_, err = p.Send(ctx, event(2))
+		r.ErrorIs(err, ErrBufferFull)
+
+		close(s.block)
+		r.NoError(p.Close(ctx))
+	})
+
+	t.Run("block", func(t *testing.T) {
+		r := require.New(t)
+		s := &sender{block: make(chan struct{})}
+		p, err := New(Opts{Client: s, MaxBufferedEvents: 1, FlushInterval: time.Millisecond})
+		r.NoError(err)
+
+		_, err = p.Send(ctx, event(1))
+		r.NoError(err)
+
+		short, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		_, err = p.Send(short, event(2))
+		r.ErrorIs(err, context.DeadlineExceeded)
+
+		// Space is freed once the first event is sent.
+		close(s.block)
+		f, err := p.Send(ctx, event(3))
+		r.NoError(err)
+		id, err := f.Wait(ctx)
+		r.NoError(err)
+		r.Equal("id-3", id)
+		r.NoError(p.Close(ctx))
+	})
+}
+
+func TestProducerCloseTimeout(t *testing.T) {
+	r := require.New(t)
+
+	s := &sender{block: make(chan struct{})}
+	p, err := New(Opts{Client: s, MaxBatchSize: 1})
+	r.NoError(err)
+
+	f1, err := p.Send(context.Background(), event(1))
+	r.NoError(err)
+	f2, err := p.Send(context.Background(), event(2))
+	r.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	r.ErrorIs(p.Close(ctx), context.DeadlineExceeded)
+
+	_, err = f1.Wait(context.Background())
+	r.ErrorIs(err, ErrClosed)
+	_, err = f2.Wait(context.Background())
+	r.ErrorIs(err, ErrClosed)
+}
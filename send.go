@@ -0,0 +1,276 @@
+package inngestgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	mathrand "math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrBadRequest is returned when sending invalid events, such as events
+	// using a reserved name.
+	ErrBadRequest = errors.New("bad request")
+	// ErrUnauthorized is returned when sending events with an invalid event key.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is returned when the event key isn't allowed to send the
+	// events, eg. due to an IP or event name allow list.
+	ErrForbidden = errors.New("forbidden")
+	// ErrPayloadTooLarge is returned when the events exceed the maximum request
+	// size.
+	ErrPayloadTooLarge = errors.New("payload too large")
+	// ErrRateLimited is returned when sending events is rate limited.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrServerError is returned when the event API fails to handle the request.
+	ErrServerError = errors.New("server error")
+)
+
+// SendError is returned when the event API responds with an error.  SendError
+// matches the ErrBadRequest, ErrUnauthorized etc. errors for its status code
+// using errors.Is, and can be inspected using errors.As:
+//
+//	var sendErr *inngestgo.SendError
+//	if errors.As(err, &sendErr) && sendErr.RetryAfter > 0 {
+//		// ...
+//	}
+type SendError struct {
+	// StatusCode is the HTTP status code returned by the event API.
+	StatusCode int
+	// Message is the error message returned by the event API, if any.
+	Message string
+	// RetryAfter is the delay requested via the Retry-After header, if any.
+	RetryAfter time.Duration
+}
+
+func (e *SendError) Error() string {
+	kind := e.Unwrap()
+	if kind == nil {
+		return fmt.Sprintf("unknown status code sending event: %d", e.StatusCode)
+	}
+	msg := e.Message
+	if msg == "" {
+		msg = "unknown error"
+	}
+	return fmt.Sprintf("%s: %s", kind, msg)
+}
+
+// Unwrap returns the error matching the status code, or nil if the status code is
+// unknown.
+func (e *SendError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusBadRequest:
+		return ErrBadRequest
+	case e.StatusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusForbidden:
+		return ErrForbidden
+	case e.StatusCode == http.StatusRequestEntityTooLarge:
+		return ErrPayloadTooLarge
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= 500:
+		return ErrServerError
+	}
+	return nil
+}
+
+// Retryable returns whether the request may succeed if retried.
+func (e *SendError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// SendRetryPolicy configures how sending events is retried.  Network errors, rate
+// limiting and server errors are retried using exponential backoff with jitter,
+// respecting any Retry-After header sent by the event API.  Retries stop as soon
+// as the context passed to Send is done.
+type SendRetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first.  This
+	// defaults to 5 if zero; set to 1 to disable retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, doubling for each retry.
+	// This defaults to 100ms.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between retries, including delays requested via
+	// Retry-After.  This defaults to 30s.
+	MaxDelay time.Duration
+}
+
+// DefaultSendRetryPolicy is the retry policy used when ClientOpts.SendRetry is nil.
+var DefaultSendRetryPolicy = SendRetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   100 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+}
+
+func (p SendRetryPolicy) withDefaults() SendRetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultSendRetryPolicy.MaxAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultSendRetryPolicy.BaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultSendRetryPolicy.MaxDelay
+	}
+	return p
+}
+
+// delay returns the delay before the given retry, starting from zero.
+func (p SendRetryPolicy) delay(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return min(retryAfter, p.MaxDelay)
+	}
+
+	// Exponential backoff with jitter between 0 and the base delay.
+	jitter := time.Duration(mathrand.Float64() * float64(p.BaseDelay))
+	backoff := float64(p.BaseDelay) * math.Pow(2, float64(attempt))
+	if backoff >= float64(p.MaxDelay) {
+		return p.MaxDelay
+	}
+	return min(time.Duration(backoff)+jitter, p.MaxDelay)
+}
+
+// parseRetryAfter parses a Retry-After header, which may be a number of seconds or
+// an HTTP date.
+func parseRetryAfter(h string, now time.Time) time.Duration {
+	if h == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(h); err == nil {
+		return max(time.Duration(secs)*time.Second, 0)
+	}
+	if t, err := http.ParseTime(h); err == nil {
+		return max(t.Sub(now), 0)
+	}
+	return 0
+}
+
+// sleep waits for the given duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// SendBatchOpts configures how SendMany splits events across requests.
+type SendBatchOpts struct {
+	// MaxEvents is the maximum number of events sent within a single request.
+	// This defaults to 5,000.
+	MaxEvents int
+	// MaxBytes is the maximum JSON-encoded size of a single request.  This
+	// defaults to 512KiB.  Events larger than this are sent alone.
+	MaxBytes int
+	// Concurrency is the maximum number of requests made concurrently.  This
+	// defaults to 4.
+	Concurrency int
+}
+
+// DefaultSendBatchOpts is the batching configuration used when
+// ClientOpts.SendBatch is nil.
+var DefaultSendBatchOpts = SendBatchOpts{
+	MaxEvents:   5_000,
+	MaxBytes:    512 * 1024,
+	Concurrency: 4,
+}
+
+func (o SendBatchOpts) withDefaults() SendBatchOpts {
+	if o.MaxEvents <= 0 {
+		o.MaxEvents = DefaultSendBatchOpts.MaxEvents
+	}
+	if o.MaxBytes <= 0 {
+		o.MaxBytes = DefaultSendBatchOpts.MaxBytes
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultSendBatchOpts.Concurrency
+	}
+	return o
+}
+
+// SendManyError is returned by SendMany when some of the requests sending a
+// split batch fail.  Events outside of the failed chunks were sent, and their IDs
+// are returned alongside the error.
+type SendManyError struct {
+	// Total is the number of events passed to SendMany.
+	Total int
+	// Failed lists the chunks of events which failed to send.
+	Failed []FailedChunk
+}
+
+// FailedChunk is a range of events which failed to send.
+type FailedChunk struct {
+	// Start and End are the indexes of the failed events, from Start up to but
+	// not including End.
+	Start int
+	End   int
+	// Err is the error sending the events.
+	Err error
+}
+
+// Indices returns the index of every event which failed to send.
+func (e *SendManyError) Indices() []int {
+	result := []int{}
+	for _, f := range e.Failed {
+		for i := f.Start; i < f.End; i++ {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+func (e *SendManyError) Error() string {
+	parts := make([]string, len(e.Failed))
+	for n, f := range e.Failed {
+		parts[n] = fmt.Sprintf("events %d-%d: %s", f.Start, f.End-1, f.Err)
+	}
+	return fmt.Sprintf(
+		"failed to send %d of %d events: %s",
+		len(e.Indices()),
+		e.Total,
+		strings.Join(parts, "; "),
+	)
+}
+
+// Unwrap returns the error of each failed chunk, allowing errors.Is and errors.As
+// to match any of them.
+func (e *SendManyError) Unwrap() []error {
+	errs := make([]error, len(e.Failed))
+	for n, f := range e.Failed {
+		errs[n] = f.Err
+	}
+	return errs
+}
+
+type sendChunk struct {
+	start, end int
+}
+
+// chunkEvents splits events into chunks of at most maxEvents events and maxBytes
+// bytes, once encoded as a JSON array.
+func chunkEvents(events []json.RawMessage, maxEvents, maxBytes int) []sendChunk {
+	chunks := []sendChunk{}
+	start, size := 0, 2
+	for i, evt := range events {
+		// Account for the separating comma.
+		n := len(evt) + 1
+		if i > start && (i-start >= maxEvents || size+n > maxBytes) {
+			chunks = append(chunks, sendChunk{start: start, end: i})
+			start, size = i, 2
+		}
+		size += n
+	}
+	if start < len(events) {
+		chunks = append(chunks, sendChunk{start: start, end: len(events)})
+	}
+	return chunks
+}
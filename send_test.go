@@ -0,0 +1,204 @@
+package inngestgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newSendClient(t *testing.T, opts ClientOpts, handler http.HandlerFunc) Client {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	opts.AppID = "app"
+	opts.EventKey = StrPtr("key")
+	opts.EventAPIBaseURL = StrPtr(srv.URL)
+	c, err := NewClient(opts)
+	require.NoError(t, err)
+	return c
+}
+
+func TestSendRetry(t *testing.T) {
+	evt := Event{Name: "test/event"}
+
+	t.Run("retries server errors", func(t *testing.T) {
+		r := require.New(t)
+		var calls int32
+		c := newSendClient(t, ClientOpts{SendRetry: &SendRetryPolicy{BaseDelay: time.Millisecond}}, func(w http.ResponseWriter, req *http.Request) {
+			if atomic.AddInt32(&calls, 1) < 3 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			_, _ = w.Write([]byte(`{"ids":["id-1"],"status":200}`))
+		})
+
+		id, err := c.Send(context.Background(), evt)
+		r.NoError(err)
+		r.Equal("id-1", id)
+		r.EqualValues(3, atomic.LoadInt32(&calls))
+	})
+
+	t.Run("respects retry-after", func(t *testing.T) {
+		r := require.New(t)
+		var (
+			calls int32
+			first time.Time
+			retry time.Time
+		)
+		c := newSendClient(t, ClientOpts{SendRetry: &SendRetryPolicy{BaseDelay: time.Millisecond}}, func(w http.ResponseWriter, req *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				first = time.Now()
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			retry = time.Now()
+			_, _ = w.Write([]byte(`{"ids":["id-1"],"status":200}`))
+		})
+
+		_, err := c.Send(context.Background(), evt)
+		r.NoError(err)
+		r.GreaterOrEqual(retry.Sub(first), time.Second)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		r := require.New(t)
+		var calls int32
+		c := newSendClient(t, ClientOpts{SendRetry: &SendRetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}}, func(w http.ResponseWriter, req *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		})
+
+		_, err := c.Send(context.Background(), evt)
+		r.ErrorIs(err, ErrRateLimited)
+		r.EqualValues(2, atomic.LoadInt32(&calls))
+	})
+
+	t.Run("stops when the context is done", func(t *testing.T) {
+		r := require.New(t)
+		c := newSendClient(t, ClientOpts{SendRetry: &SendRetryPolicy{MaxAttempts: 100, BaseDelay: time.Hour}}, func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := c.Send(ctx, evt)
+		r.ErrorIs(err, ErrServerError)
+		r.Less(time.Since(start), time.Second)
+	})
+}
+
+func TestSendErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		target error
+		msg    string
+	}{
+		{http.StatusBadRequest, ErrBadRequest, "bad request: invalid event"},
+		{http.StatusUnauthorized, ErrUnauthorized, "unauthorized: invalid event"},
+		{http.StatusForbidden, ErrForbidden, "forbidden: invalid event"},
+		{http.StatusRequestEntityTooLarge, ErrPayloadTooLarge, "payload too large: invalid event"},
+	}
+
+	for _, test := range tests {
+		t.Run(http.StatusText(test.status), func(t *testing.T) {
+			r := require.New(t)
+			var calls int32
+			c := newSendClient(t, ClientOpts{}, func(w http.ResponseWriter, req *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(`{"error":"invalid event"}`))
+			})
+
+			_, err := c.Send(context.Background(), Event{Name: "test/event"})
+			r.ErrorIs(err, test.target)
+			r.EqualError(err, test.msg)
+
+			var sendErr *SendError
+			r.True(errors.As(err, &sendErr))
+			r.Equal(test.status, sendErr.StatusCode)
+			r.Equal("invalid event", sendErr.Message)
+
+			// Client errors aren't retried.
+			r.EqualValues(1, atomic.LoadInt32(&calls))
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	r := require.New(t)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	r.Equal(time.Duration(0), parseRetryAfter("", now))
+	r.Equal(5*time.Second, parseRetryAfter("5", now))
+	r.Equal(time.Minute, parseRetryAfter(now.Add(time.Minute).Format(http.TimeFormat), now))
+	r.Equal(time.Duration(0), parseRetryAfter("invalid", now))
+}
+
+func TestChunkEvents(t *testing.T) {
+	r := require.New(t)
+	events := []json.RawMessage{
+		json.RawMessage(`{"name":"a"}`), // 12 bytes
+		json.RawMessage(`{"name":"b"}`),
+		json.RawMessage(`{"name":"c"}`),
+		json.RawMessage(`{"name":"d","data":{"large":true}}`),
+		json.RawMessage(`{"name":"e"}`),
+	}
+
+	r.Equal([]sendChunk{{0, 2}, {2, 4}, {4, 5}}, chunkEvents(events, 2, 1000))
+	// Two small events fit within 2 + 2*13 bytes, and large events are sent alone.
+	r.Equal([]sendChunk{{0, 2}, {2, 3}, {3, 4}, {4, 5}}, chunkEvents(events, 10, 28))
+	r.Equal([]sendChunk{{0, 5}}, chunkEvents(events, 10, 1000))
+	r.Empty(chunkEvents(nil, 10, 1000))
+}
+
+func TestSendManyChunks(t *testing.T) {
+	r := require.New(t)
+
+	var calls int32
+	c := newSendClient(t, ClientOpts{
+		SendRetry: &SendRetryPolicy{MaxAttempts: 1},
+		SendBatch: &SendBatchOpts{MaxEvents: 3, Concurrency: 2},
+	}, func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		events := []Event{}
+		_ = json.NewDecoder(req.Body).Decode(&events)
+
+		ids := []string{}
+		for _, evt := range events {
+			if evt.Data["fail"] == true {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"invalid event"}`))
+				return
+			}
+			ids = append(ids, fmt.Sprintf("id-%v", evt.Data["i"]))
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"ids": ids, "status": 200})
+	})
+
+	events := []any{}
+	for i := 0; i < 10; i++ {
+		events = append(events, Event{Name: "test/event", Data: map[string]any{"i": i, "fail": i == 4}})
+	}
+
+	ids, err := c.SendMany(context.Background(), events)
+	r.EqualValues(4, atomic.LoadInt32(&calls))
+	r.Equal([]string{"id-0", "id-1", "id-2", "", "", "", "id-6", "id-7", "id-8", "id-9"}, ids)
+
+	var sendErr *SendManyError
+	r.True(errors.As(err, &sendErr))
+	r.Equal([]int{3, 4, 5}, sendErr.Indices())
+	r.ErrorIs(err, ErrBadRequest)
+	r.EqualError(err, "failed to send 3 of 10 events: events 3-5: bad request: invalid event")
+}
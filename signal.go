@@ -0,0 +1,156 @@
+package inngestgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/inngest/inngestgo/step"
+)
+
+var (
+	// ErrSignalNotFound is returned by SendSignal when no run is waiting for the
+	// signal.
+	ErrSignalNotFound = errors.New("signal not found")
+	// ErrSignalConflict is returned by SendSignal when the signal can't be
+	// delivered due to a conflicting signal, eg. if it has been replaced.
+	ErrSignalConflict = errors.New("signal conflict")
+)
+
+// SignalError is returned when sending a signal fails.  SignalError matches
+// ErrSignalNotFound and ErrSignalConflict using errors.Is.
+type SignalError struct {
+	// Signal is the signal which was sent.
+	Signal string
+	// StatusCode is the HTTP status code returned by the API.
+	StatusCode int
+	// Message is the error message returned by the API.
+	Message string
+}
+
+func (e *SignalError) Error() string {
+	return fmt.Sprintf("error sending signal '%s' (%d): %s", e.Signal, e.StatusCode, e.Message)
+}
+
+func (e *SignalError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return ErrSignalNotFound
+	case http.StatusConflict:
+		return ErrSignalConflict
+	}
+	return nil
+}
+
+type signalRequest struct {
+	Signal string `json:"signal"`
+	Data   any    `json:"data"`
+}
+
+type signalResponse struct {
+	Data struct {
+		RunID string `json:"run_id"`
+	} `json:"data"`
+	Error string `json:"error"`
+}
+
+func (a apiClient) SendSignal(ctx context.Context, signal string, data any) (string, error) {
+	if signal == "" {
+		return "", fmt.Errorf("signal is required")
+	}
+
+	byt, err := json.Marshal(signalRequest{Signal: signal, Data: data})
+	if err != nil {
+		return "", fmt.Errorf("error marshalling signal data: %w", err)
+	}
+
+	h := a.h
+	keys := h.signingKeys(ctx)
+	resp, err := fetchWithAuthFallback(
+		func() (*http.Request, error) {
+			req, err := http.NewRequestWithContext(
+				ctx,
+				http.MethodPost,
+				fmt.Sprintf("%s/v1/signals", h.GetAPIBaseURL()),
+				bytes.NewReader(byt),
+			)
+			if err != nil {
+				return nil, err
+			}
+			SetBasicRequestHeaders(req)
+			req.Header.Set("Content-Type", "application/json")
+			if h.GetEnv() != "" {
+				req.Header.Add(HeaderKeyEnv, h.GetEnv())
+			}
+			return req, nil
+		},
+		keys.Current,
+		keys.Fallback,
+	)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading response: %w", err)
+	}
+
+	r := signalResponse{}
+	_ = json.Unmarshal(body, &r)
+
+	if resp.StatusCode > 299 {
+		msg := r.Error
+		if msg == "" {
+			msg = string(body)
+		}
+		return "", &SignalError{Signal: signal, StatusCode: resp.StatusCode, Message: msg}
+	}
+	return r.Data.RunID, nil
+}
+
+// SignalType declares a signal alongside the type of its data, ensuring that the
+// data sent matches the data waited for:
+//
+//	var Approval = inngestgo.NewSignalType[ApprovalData]()
+//
+//	// Within a function, wait for a signal unique to the run.
+//	approval, err := Approval.WaitFor(ctx, "wait-for-approval", "approval-"+orderID, time.Hour)
+//
+//	// Elsewhere, resume the run.
+//	_, err := Approval.Send(ctx, client, "approval-"+orderID, ApprovalData{Approved: true})
+type SignalType[T any] struct{}
+
+// NewSignalType returns a SignalType whose data is of type T.
+func NewSignalType[T any]() SignalType[T] {
+	return SignalType[T]{}
+}
+
+// Send sends the signal, resuming the run waiting for it and returning the run's
+// ID.  If no run is waiting for the signal, this returns an error matching
+// ErrSignalNotFound.  The client must implement SignalSender.
+func (SignalType[T]) Send(ctx context.Context, c Client, signal string, data T) (string, error) {
+	sender, ok := c.(SignalSender)
+	if !ok {
+		return "", fmt.Errorf("client doesn't support sending signals")
+	}
+	return sender.SendSignal(ctx, signal, data)
+}
+
+// WaitFor pauses function execution until the signal is received or the wait
+// times out, returning step.ErrSignalNotReceived on timeout.
+func (SignalType[T]) WaitFor(ctx context.Context, stepID string, signal string, timeout time.Duration) (T, error) {
+	res, err := step.WaitForSignal[T](ctx, stepID, step.WaitForSignalOpts{
+		Signal:  signal,
+		Timeout: timeout,
+	})
+	return res.Data, err
+}
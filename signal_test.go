@@ -0,0 +1,65 @@
+package inngestgo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendSignal(t *testing.T) {
+	type Approval struct {
+		Approved bool `json:"approved"`
+	}
+
+	r := require.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.Equal(http.MethodPost, req.Method)
+		r.Equal("/v1/signals", req.URL.Path)
+
+		body := struct {
+			Signal string   `json:"signal"`
+			Data   Approval `json:"data"`
+		}{}
+		r.NoError(json.NewDecoder(req.Body).Decode(&body))
+
+		switch body.Signal {
+		case "approval-1":
+			r.True(body.Data.Approved)
+			_, _ = w.Write([]byte(`{"data":{"run_id":"run-1"}}`))
+		case "conflict":
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"error":"signal replaced"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"no run waiting for signal"}`))
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(ClientOpts{AppID: "app", Dev: Ptr(true), APIBaseURL: StrPtr(srv.URL)})
+	r.NoError(err)
+
+	approval := NewSignalType[Approval]()
+	runID, err := approval.Send(context.Background(), c, "approval-1", Approval{Approved: true})
+	r.NoError(err)
+	r.Equal("run-1", runID)
+
+	_, err = c.(SignalSender).SendSignal(context.Background(), "missing", nil)
+	r.ErrorIs(err, ErrSignalNotFound)
+	r.EqualError(err, "error sending signal 'missing' (404): no run waiting for signal")
+
+	_, err = c.(SignalSender).SendSignal(context.Background(), "conflict", nil)
+	r.ErrorIs(err, ErrSignalConflict)
+
+	var sigErr *SignalError
+	r.ErrorAs(err, &sigErr)
+	r.Equal("conflict", sigErr.Signal)
+
+	_, err = c.(SignalSender).SendSignal(context.Background(), "", nil)
+	r.Error(err)
+}
@@ -14,13 +14,25 @@ import (
 
 	"github.com/gowebpki/jcs"
 	"github.com/inngest/inngestgo/internal/logger"
+	"github.com/inngest/inngestgo/pkg/replay"
+	"github.com/inngest/inngestgo/pkg/signingkey"
 )
 
+// SigningKeyProvider supplies signing keys whenever they're used, allowing keys
+// to be rotated without restarting.  See the signingkey package for
+// implementations.
+type SigningKeyProvider = signingkey.Provider
+
+// ReplayStore records the signatures of accepted requests, allowing replayed
+// requests to be rejected.  See the replay package for implementations.
+type ReplayStore = replay.Store
+
 var (
 	ErrExpiredSignature  = fmt.Errorf("expired signature")
 	ErrInvalidSignature  = fmt.Errorf("invalid signature")
 	ErrInvalidTimestamp  = fmt.Errorf("invalid timestamp")
 	ErrMissingSigningKey = fmt.Errorf("missing signing key")
+	ErrReplayedSignature = fmt.Errorf("replayed signature")
 
 	keyRegexp             = regexp.MustCompile(`^signkey-\w+-`)
 	signatureTimeDeltaMax = 5 * time.Minute
@@ -145,6 +157,33 @@ func ValidateRequestSignature(
 	return false, correctKey, err
 }
 
+// CheckReplayedSignature records a validated request signature in the given
+// store, returning ErrReplayedSignature if the signature has already been used.
+// Signatures are held until they expire.  This is a no-op if the store is nil.
+func CheckReplayedSignature(ctx context.Context, store ReplayStore, sig string) error {
+	if store == nil || sig == "" {
+		return nil
+	}
+
+	val, err := url.ParseQuery(sig)
+	if err != nil || val.Get("t") == "" {
+		return ErrInvalidSignature
+	}
+	ts, err := strconv.Atoi(val.Get("t"))
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+
+	ok, err := store.Add(ctx, sig, time.Unix(int64(ts), 0).Add(signatureTimeDeltaMax))
+	if err != nil {
+		return fmt.Errorf("error checking signature for replay: %w", err)
+	}
+	if !ok {
+		return ErrReplayedSignature
+	}
+	return nil
+}
+
 // ValidateResponseSignature validates the response signature. It's the same as
 // request signature validation except doesn't perform canonicalization.
 func ValidateResponseSignature(ctx context.Context, sig string, key, body []byte) (bool, error) {
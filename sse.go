@@ -0,0 +1,103 @@
+package inngestgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/inngest/inngestgo/internal/sdkrequest"
+)
+
+const (
+	// sseEventHeartbeat is sent periodically to keep the connection alive.
+	sseEventHeartbeat = "heartbeat"
+	// sseEventStep is sent as each step op is reported by the function.
+	sseEventStep = "step"
+	// sseEventResult is sent once with the invocation's StreamResponse, after
+	// which the stream ends.
+	sseEventResult = "result"
+)
+
+type opStreamCtxKeyType struct{}
+
+var opStreamCtxKey = opStreamCtxKeyType{}
+
+// withOpStream returns a context which reports each step op to the given func as
+// it's appended during invocation.
+func withOpStream(ctx context.Context, f func(op sdkrequest.GeneratorOpcode)) context.Context {
+	return context.WithValue(ctx, opStreamCtxKey, f)
+}
+
+func opStream(ctx context.Context) func(op sdkrequest.GeneratorOpcode) {
+	f, _ := ctx.Value(opStreamCtxKey).(func(op sdkrequest.GeneratorOpcode))
+	return f
+}
+
+// acceptsEventStream returns whether the request accepts server-sent events.
+// Executors which don't support them expect a JSON response, and don't send
+// "text/event-stream" in their Accept header.
+func acceptsEventStream(r *http.Request) bool {
+	for _, v := range r.Header.Values(HeaderKeyAccept) {
+		for _, part := range strings.Split(v, ",") {
+			mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err == nil && mediaType == "text/event-stream" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sseWriter writes server-sent events to a response.  Step events are written
+// from the function's goroutine while heartbeats and the result are written from
+// the handler's, so writes are serialized.  Once closed, further events are
+// dropped, as the response can't be written after the handler returns.
+type sseWriter struct {
+	l      sync.Mutex
+	w      http.ResponseWriter
+	closed bool
+}
+
+func newSSEWriter(w http.ResponseWriter) *sseWriter {
+	w.Header().Set(HeaderKeyContentType, "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	return &sseWriter{w: w}
+}
+
+// event writes a single event with the given name, ID and JSON-encoded data.
+func (s *sseWriter) event(name string, id string, data any) error {
+	byt, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("error encoding %s event: %w", name, err)
+	}
+
+	s.l.Lock()
+	defer s.l.Unlock()
+	if s.closed {
+		return nil
+	}
+
+	msg := "event: " + name + "\n"
+	if id != "" {
+		msg += "id: " + id + "\n"
+	}
+	msg += "data: " + string(byt) + "\n\n"
+	if _, err := s.w.Write([]byte(msg)); err != nil {
+		return err
+	}
+	if flusher, ok := s.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+func (s *sseWriter) close() {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.closed = true
+}
@@ -0,0 +1,166 @@
+package inngestgo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/inngest/inngest/pkg/enums"
+	sdkerrors "github.com/inngest/inngestgo/errors"
+	"github.com/inngest/inngestgo/internal/sdkrequest"
+	"github.com/inngest/inngestgo/internal/types"
+	"github.com/inngest/inngestgo/step"
+	"github.com/stretchr/testify/require"
+)
+
+type sseEvent struct {
+	name string
+	id   string
+	data string
+}
+
+func TestServerSentEvents(t *testing.T) {
+	setEnvVars(t)
+
+	c, err := NewClient(ClientOpts{AppID: "sse", UseServerSentEvents: true})
+	require.NoError(t, err)
+
+	retryAt := time.Now().Add(time.Hour).Truncate(time.Second).UTC()
+	fn, err := CreateFunction(
+		c,
+		FunctionOpts{ID: "fn"},
+		EventTrigger("test/event.a", nil),
+		func(ctx context.Context, input Input[EventAData]) (any, error) {
+			if input.Event.Data.Foo == "retry" {
+				return nil, sdkerrors.RetryAtError(errors.New("not yet"), retryAt)
+			}
+			return step.Run(ctx, "first", func(ctx context.Context) (string, error) {
+				return "hello", nil
+			})
+		},
+	)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(c.Serve())
+	defer server.Close()
+
+	q := url.Values{}
+	q.Add("fnId", fn.FullyQualifiedID())
+	urlStr := fmt.Sprintf("%s?%s", server.URL, q.Encode())
+
+	post := func(t *testing.T, foo string, accept string) *http.Response {
+		body := marshalRequest(t, createRequest(t, EventA{
+			Name: "test/event.a",
+			Data: EventAData{Foo: foo},
+		}))
+		sig, _ := Sign(context.Background(), time.Now(), []byte(testKey), body)
+
+		req, err := http.NewRequest(http.MethodPost, urlStr, bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set(HeaderKeySignature, sig)
+		if accept != "" {
+			req.Header.Set(HeaderKeyAccept, accept)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	stream := func(t *testing.T, foo string) []sseEvent {
+		resp := post(t, foo, "application/json, text/event-stream")
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		require.Equal(t, "text/event-stream", resp.Header.Get(HeaderKeyContentType))
+
+		var (
+			events  []sseEvent
+			current sseEvent
+		)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			field, value, _ := strings.Cut(scanner.Text(), ": ")
+			switch field {
+			case "event":
+				current.name = value
+			case "id":
+				current.id = value
+			case "data":
+				current.data = value
+			case "":
+				events = append(events, current)
+				current = sseEvent{}
+			}
+		}
+		require.NoError(t, scanner.Err())
+		return events
+	}
+
+	t.Run("steps", func(t *testing.T) {
+		r := require.New(t)
+		events := stream(t, "step")
+		r.Len(events, 2)
+
+		r.Equal(sseEventStep, events[0].name)
+		var op sdkrequest.GeneratorOpcode
+		r.NoError(json.Unmarshal([]byte(events[0].data), &op))
+		r.Equal(enums.OpcodeStepRun, op.Op)
+		r.Equal(op.ID, events[0].id)
+
+		r.Equal(sseEventResult, events[1].name)
+		var res StreamResponse
+		r.NoError(json.Unmarshal([]byte(events[1].data), &res))
+		r.Equal(206, res.StatusCode)
+	})
+
+	t.Run("retry at", func(t *testing.T) {
+		r := require.New(t)
+		events := stream(t, "retry")
+		r.Len(events, 1)
+
+		r.Equal(sseEventResult, events[0].name)
+		var res StreamResponse
+		r.NoError(json.Unmarshal([]byte(events[0].data), &res))
+		r.Equal(500, res.StatusCode)
+		r.False(res.NoRetry)
+		r.NotNil(res.RetryAt)
+		r.True(retryAt.Equal(*res.RetryAt))
+		r.Equal(retryAt.Format(time.RFC3339), res.Headers[HeaderKeyRetryAfter])
+	})
+
+	t.Run("not accepted", func(t *testing.T) {
+		// Executors which don't accept server-sent events receive the usual
+		// JSON response.
+		r := require.New(t)
+		resp := post(t, "step", "")
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		r.Equal(206, resp.StatusCode)
+		r.Equal("application/json", resp.Header.Get(HeaderKeyContentType))
+
+		var ops []sdkrequest.GeneratorOpcode
+		r.NoError(json.NewDecoder(resp.Body).Decode(&ops))
+		r.Len(ops, 1)
+		r.Equal(enums.OpcodeStepRun, ops[0].Op)
+	})
+}
+
+func TestServerSentEventsCapability(t *testing.T) {
+	r := require.New(t)
+	r.Empty(handlerOpts{}.capabilities().ServerSentEvents)
+	r.Equal(
+		types.ServerSentEventsV1,
+		handlerOpts{UseServerSentEvents: true}.capabilities().ServerSentEvents,
+	)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/inngest/inngestgo"
 	"github.com/inngest/inngestgo/internal/sdkrequest"
+	"github.com/inngest/inngestgo/pkg/signingkey"
 	"github.com/oklog/ulid/v2"
 )
 
@@ -120,22 +121,27 @@ type APIResult struct {
 
 // APIClient handles HTTP requests to the checkpoint API
 type APIClient struct {
-	baseURL     string
-	primaryKey  string
-	fallbackKey string
-	httpClient  *http.Client
-	// useFallback tracks whether to use the fallback key (1) or primary key (0)
-	useFallback *atomic.Bool
+	baseURL    string
+	keys       signingkey.Provider
+	httpClient *http.Client
+	// rejectedKey records the primary key which was rejected by the API, in
+	// which case the fallback key is used until the primary key changes.
+	rejectedKey *atomic.Pointer[string]
 }
 
 // NewAPIClient creates a new API client with the given domain and signing keys
 func NewAPIClient(baseURL, primaryKey, fallbackKey string) *APIClient {
+	return newAPIClient(baseURL, signingkey.Static(primaryKey, fallbackKey))
+}
+
+// newAPIClient creates a new API client which loads signing keys from the given
+// provider for every request.
+func newAPIClient(baseURL string, keys signingkey.Provider) *APIClient {
 	return &APIClient{
 		baseURL:     baseURL,
-		primaryKey:  primaryKey,
-		fallbackKey: fallbackKey,
+		keys:        keys,
 		httpClient:  &http.Client{Timeout: 30 * time.Second},
-		useFallback: &atomic.Bool{},
+		rejectedKey: &atomic.Pointer[string]{},
 	}
 }
 
@@ -251,8 +257,18 @@ func (c *APIClient) doSingle(ctx context.Context, method, path string, payload a
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	keys, err := c.keys.SigningKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load signing keys: %w", err)
+	}
+	useFallback := keys.Fallback != "" && c.usingFallback(keys)
+
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.getCurrentSigningKey())
+	if useFallback {
+		req.Header.Set("Authorization", "Bearer "+keys.Fallback)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+keys.Current)
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -269,8 +285,8 @@ func (c *APIClient) doSingle(ctx context.Context, method, path string, payload a
 
 	if resp.StatusCode >= 400 {
 		// If we get a 401 and have a fallback key, try switching to it
-		if resp.StatusCode == 401 && c.fallbackKey != "" && !c.useFallback.Load() {
-			c.useFallback.Store(true)
+		if resp.StatusCode == 401 && keys.Fallback != "" && !useFallback {
+			c.rejectedKey.Store(&keys.Current)
 			// Retry the request with the fallback key
 			return c.doSingle(ctx, method, path, payload)
 		}
@@ -280,10 +296,9 @@ func (c *APIClient) doSingle(ctx context.Context, method, path string, payload a
 	return byt, err
 }
 
-// getCurrentSigningKey returns the appropriate signing key based on rotation state
-func (c *APIClient) getCurrentSigningKey() string {
-	if c.useFallback.Load() {
-		return c.fallbackKey
-	}
-	return c.primaryKey
+// usingFallback returns whether the fallback key should be used, which is the
+// case once the current primary key has been rejected.
+func (c *APIClient) usingFallback(keys signingkey.Keys) bool {
+	rejected := c.rejectedKey.Load()
+	return rejected != nil && *rejected == keys.Current
 }
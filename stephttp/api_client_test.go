@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/inngest/inngestgo/pkg/signingkey"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -205,6 +206,38 @@ func TestAPIClient_SigningKeyRotation(t *testing.T) {
 	}
 }
 
+func TestAPIClient_RotatedKeys(t *testing.T) {
+	r := require.New(t)
+
+	var usedKeys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		auth := req.Header.Get("Authorization")
+		usedKeys = append(usedKeys, auth)
+		if auth == "Bearer primary-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": {}}`))
+	}))
+	defer server.Close()
+
+	keys := signingkey.Keys{Current: "primary-key", Fallback: "fallback-key"}
+	client := newAPIClient(server.URL, signingkey.ProviderFunc(func(ctx context.Context) (signingkey.Keys, error) {
+		return keys, nil
+	}))
+
+	_, err := client.do(context.Background(), "GET", "/test", nil)
+	r.NoError(err)
+	r.Equal([]string{"Bearer primary-key", "Bearer fallback-key"}, usedKeys)
+
+	// Once the provider returns a new primary key, it's used immediately.
+	keys = signingkey.Keys{Current: "rotated-key", Fallback: "fallback-key"}
+	usedKeys = nil
+	_, err = client.do(context.Background(), "GET", "/test", nil)
+	r.NoError(err)
+	r.Equal([]string{"Bearer rotated-key"}, usedKeys)
+}
+
 func TestAPIClient_NoFallbackKey(t *testing.T) {
 	var callCount atomic.Int32
 
@@ -8,9 +8,11 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/inngest/inngestgo"
 	"github.com/inngest/inngestgo/internal/logger"
 	"github.com/inngest/inngestgo/middleware"
 	"github.com/inngest/inngestgo/pkg/env"
+	"github.com/inngest/inngestgo/pkg/signingkey"
 )
 
 const (
@@ -56,6 +58,13 @@ type OptionalSetupOpts struct {
 	// SigningKeyFallback is the optional signing key fallback. If empty, this defaults
 	// to os.Getenv("INNGEST_SIGNING_KEY_FALLBACK").
 	SigningKeyFallback string
+	// SigningKeyProvider optionally supplies signing keys for every request,
+	// allowing keys to be rotated without restarting.  If set, this takes
+	// precedence over SigningKey and SigningKeyFallback.
+	SigningKeyProvider signingkey.Provider
+	// ReplayStore, if set, records the signature of every resume request from
+	// Inngest, ignoring resume requests whose signature has already been used.
+	ReplayStore inngestgo.ReplayStore
 	// BaseURL is the URL of the Inngest API.  If empty, this:
 	//
 	//   1. Checks to see if INNGEST_DEV is set, indicating dev mode.  If set, we
@@ -82,6 +91,22 @@ func (o SetupOpts) signingKeyFallback() string {
 	return o.Optional.SigningKeyFallback
 }
 
+// signingKeys returns the signing keys to use for a request, consulting the
+// SigningKeyProvider if set.  If the provider fails, the static keys are used.
+func (p *provider) signingKeys(ctx context.Context) signingkey.Keys {
+	if p.opts.Optional.SigningKeyProvider != nil {
+		keys, err := p.opts.Optional.SigningKeyProvider.SigningKeys(ctx)
+		if err == nil {
+			return keys
+		}
+		p.logger.Error("error loading signing keys from provider", "error", err)
+	}
+	return signingkey.Keys{
+		Current:  p.opts.signingKey(),
+		Fallback: p.opts.signingKeyFallback(),
+	}
+}
+
 func (o SetupOpts) baseURL() string {
 	if o.Optional.BaseURL != "" {
 		return o.Optional.BaseURL
@@ -116,7 +141,9 @@ func Setup(opts SetupOpts) *provider {
 		logger:   logger.Default(),
 	}
 
-	p.api = NewAPIClient(p.opts.baseURL(), p.opts.signingKey(), p.opts.signingKeyFallback())
+	p.api = newAPIClient(p.opts.baseURL(), signingkey.ProviderFunc(func(ctx context.Context) (signingkey.Keys, error) {
+		return p.signingKeys(ctx), nil
+	}))
 
 	return p
 }
@@ -27,7 +27,7 @@ func processRequest(p *provider, r *http.Request, w http.ResponseWriter, next ht
 		next:     next,
 		provider: p,
 		mgr: sdkrequest.NewManager(sdkrequest.Opts{
-			SigningKey: p.opts.signingKey(),
+			SigningKey: p.signingKeys(r.Context()).Current,
 			Mode:       sdkrequest.StepModeManual,
 			APIBaseURL: env.APIServerURL(nil),
 		}),
@@ -216,9 +216,22 @@ func (o *requestOwner) handleAsyncConversion(ctx context.Context, token string)
 
 func (o *requestOwner) getExistingRun(ctx context.Context) bool {
 	// Validate signature and extract run information
-	if !validateResumeRequestSignature(ctx, o.r, o.provider.opts.signingKey(), o.provider.opts.signingKeyFallback()) {
+	keys := o.provider.signingKeys(ctx)
+	if !validateResumeRequestSignature(ctx, o.r, keys.Current, keys.Fallback) {
 		return false
 	}
+	if !env.IsDev() {
+		err := inngestgo.CheckReplayedSignature(ctx, o.provider.opts.Optional.ReplayStore, o.r.Header.Get(headerSignature))
+		if err != nil {
+			o.provider.logger.Warn(
+				"rejecting resume request",
+				"error", err,
+				"run_id", o.r.Header.Get(headerRunID),
+				"request_id", o.r.Header.Get(inngestgo.HeaderKeyRequestID),
+			)
+			return false
+		}
+	}
 
 	// Extract headers after validation passes
 	runID, err := ulid.Parse(o.r.Header.Get(headerRunID))
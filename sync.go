@@ -0,0 +1,124 @@
+package inngestgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// SyncOpts configures Client.Sync.
+type SyncOpts struct {
+	// URL is the URL that the app is served at.  If nil, this is taken from
+	// ClientOpts.URL, ServeOpts.Origin and ServeOpts.Path, or the
+	// INNGEST_SERVE_HOST and INNGEST_SERVE_PATH environment variables.
+	URL *url.URL
+	// Retry configures how failed syncs are retried, in the same way as sending
+	// events.  Network errors, rate limiting and server errors are retried.
+	Retry SendRetryPolicy
+}
+
+// registerError is returned when registering an app with Inngest fails.
+type registerError struct {
+	// statusCode is the status returned by Inngest, or zero if the request
+	// failed.
+	statusCode int
+	err        error
+}
+
+func (e *registerError) Error() string {
+	return e.err.Error()
+}
+
+func (e *registerError) Unwrap() error {
+	return e.err
+}
+
+func (e *registerError) retryable() bool {
+	return e.statusCode == 0 || e.statusCode == http.StatusTooManyRequests || e.statusCode >= 500
+}
+
+// Sync syncs the app's functions with Inngest directly from the process, in the
+// same way as an out-of-band sync via a PUT request to the serve handler.  This
+// is safe to call from many replicas at once, as replicas with the same
+// functions and version send the same idempotency key.
+func (a apiClient) Sync(ctx context.Context, opts SyncOpts) error {
+	return a.h.sync(ctx, opts)
+}
+
+func (h *handler) sync(ctx context.Context, opts SyncOpts) error {
+	appURL := opts.URL
+	if appURL == nil {
+		var err error
+		if appURL, err = configuredURL(h.handlerOpts); err != nil {
+			h.Logger.Error("sync error", "error", err, "syncKind", SyncKindOutOfBand)
+			h.recordSync(SyncKindOutOfBand, err)
+			return err
+		}
+	}
+
+	retry := opts.Retry.withDefaults()
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = h.registerApp(ctx, appURL, "", "")
+		if err == nil {
+			h.Logger.Info("synced app", "url", appURL.String(), "attempts", attempt+1)
+			h.recordSync(SyncKindOutOfBand, nil)
+			return nil
+		}
+
+		var rerr *registerError
+		if !errors.As(err, &rerr) || !rerr.retryable() || attempt+1 >= retry.MaxAttempts {
+			break
+		}
+
+		delay := retry.delay(attempt, 0)
+		h.Logger.Warn("error syncing app, retrying", "error", err, "attempt", attempt+1, "delay", delay)
+		if serr := sleep(ctx, delay); serr != nil {
+			err = errors.Join(err, serr)
+			break
+		}
+	}
+
+	err = fmt.Errorf("error syncing app: %w", err)
+	h.Logger.Error("sync error", "error", err, "syncKind", SyncKindOutOfBand)
+	h.recordSync(SyncKindOutOfBand, err)
+	return err
+}
+
+// syncOnStart schedules a sync with ServeOpts.SyncOnStart, the first time that
+// the app is served.  The sync starts in the background on the first request to
+// the serve or health handler, rather than when serving, so that it includes
+// functions created after the handler.  If the app is served by MultiAppHandler,
+// it's synced with its app ID in the URL.
+func (h *handler) syncOnStart(multiApp bool) {
+	h.syncOnce.Do(func() {
+		opts := SyncOpts{}
+		if multiApp {
+			appURL, err := configuredURL(h.handlerOpts)
+			if err != nil {
+				h.Logger.Error("sync error", "error", err, "syncKind", SyncKindOutOfBand)
+				h.recordSync(SyncKindOutOfBand, err)
+				return
+			}
+			q := appURL.Query()
+			q.Set(multiAppQueryParam, h.appName)
+			appURL.RawQuery = q.Encode()
+			opts.URL = appURL
+		}
+		h.pendingSync.Store(&opts)
+	})
+}
+
+// startSync starts the sync scheduled by syncOnStart, if any.
+func (h *handler) startSync() {
+	if h.pendingSync.Load() == nil {
+		return
+	}
+	if opts := h.pendingSync.Swap(nil); opts != nil {
+		go func() {
+			_ = h.sync(context.Background(), *opts)
+		}()
+	}
+}
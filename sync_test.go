@@ -0,0 +1,229 @@
+package inngestgo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/inngest/inngestgo/internal/types"
+	"github.com/stretchr/testify/require"
+)
+
+// syncRegistry records sync requests, responding with the given statuses in
+// turn and then succeeding.
+type syncRegistry struct {
+	l        sync.Mutex
+	statuses []int
+	synced   []types.RegisterRequest
+}
+
+func (s *syncRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body types.RegisterRequest
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.synced = append(s.synced, body)
+	if len(s.statuses) > 0 {
+		w.WriteHeader(s.statuses[0])
+		_, _ = w.Write([]byte(`{"error":"sync failed"}`))
+		s.statuses = s.statuses[1:]
+	}
+}
+
+func (s *syncRegistry) requests() []types.RegisterRequest {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return append([]types.RegisterRequest{}, s.synced...)
+}
+
+func TestSync(t *testing.T) {
+	appURL, err := url.Parse("https://example.com/api/inngest")
+	require.NoError(t, err)
+	retry := SendRetryPolicy{BaseDelay: time.Millisecond}
+
+	newClient := func(t *testing.T, registry *syncRegistry, opts ClientOpts) Client {
+		server := httptest.NewServer(registry)
+		t.Cleanup(server.Close)
+
+		opts.AppID = "sync"
+		opts.Dev = BoolPtr(true)
+		opts.RegisterURL = StrPtr(server.URL)
+		return newSyncClient(t, opts, map[string]string{"fn": "Function"})
+	}
+
+	t.Run("retries server errors", func(t *testing.T) {
+		r := require.New(t)
+		registry := &syncRegistry{statuses: []int{http.StatusInternalServerError, http.StatusBadGateway}}
+		c := newClient(t, registry, ClientOpts{URL: appURL})
+
+		r.NoError(c.(Syncer).Sync(context.Background(), SyncOpts{Retry: retry}))
+		synced := registry.requests()
+		r.Len(synced, 3)
+		r.Equal("https://example.com/api/inngest", synced[2].URL)
+		r.NotEmpty(synced[2].IdempotencyKey)
+		r.Equal(synced[0].IdempotencyKey, synced[2].IdempotencyKey)
+
+		status := c.(*apiClient).h.health().LastSync
+		r.NotNil(status)
+		r.True(status.Succeeded)
+	})
+
+	t.Run("doesn't retry client errors", func(t *testing.T) {
+		r := require.New(t)
+		registry := &syncRegistry{statuses: []int{http.StatusBadRequest}}
+		c := newClient(t, registry, ClientOpts{URL: appURL})
+
+		err := c.(Syncer).Sync(context.Background(), SyncOpts{Retry: retry})
+		r.ErrorContains(err, "sync failed")
+		r.Len(registry.requests(), 1)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		r := require.New(t)
+		registry := &syncRegistry{statuses: []int{500, 500, 500}}
+		c := newClient(t, registry, ClientOpts{URL: appURL})
+
+		retry := retry
+		retry.MaxAttempts = 2
+		r.Error(c.(Syncer).Sync(context.Background(), SyncOpts{Retry: retry}))
+		r.Len(registry.requests(), 2)
+	})
+
+	t.Run("URL override", func(t *testing.T) {
+		r := require.New(t)
+		registry := &syncRegistry{}
+		c := newClient(t, registry, ClientOpts{})
+
+		override, err := url.Parse("https://override.example.com/inngest")
+		r.NoError(err)
+		r.NoError(c.(Syncer).Sync(context.Background(), SyncOpts{URL: override}))
+		r.Equal("https://override.example.com/inngest", registry.requests()[0].URL)
+	})
+
+	t.Run("doesn't block requests", func(t *testing.T) {
+		r := require.New(t)
+		release := make(chan struct{})
+		registering := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			close(registering)
+			<-release
+		}))
+		defer server.Close()
+
+		c := newSyncClient(t, ClientOpts{
+			AppID:       "sync",
+			Dev:         BoolPtr(true),
+			RegisterURL: StrPtr(server.URL),
+			URL:         appURL,
+		}, map[string]string{"fn": "Function"})
+
+		synced := make(chan error)
+		go func() { synced <- c.(Syncer).Sync(context.Background(), SyncOpts{}) }()
+		<-registering
+
+		// Functions can be looked up while the registration request is in
+		// flight.
+		r.NotNil(c.(*apiClient).h.function("sync-fn"))
+		close(release)
+		r.NoError(<-synced)
+	})
+
+	t.Run("no URL", func(t *testing.T) {
+		t.Setenv("INNGEST_SERVE_HOST", "")
+		registry := &syncRegistry{}
+		c := newClient(t, registry, ClientOpts{})
+
+		err := c.(Syncer).Sync(context.Background(), SyncOpts{})
+		require.ErrorContains(t, err, "no serve URL configured")
+		require.Empty(t, registry.requests())
+	})
+}
+
+func TestSyncOnStart(t *testing.T) {
+	t.Run("single app", func(t *testing.T) {
+		registry := &syncRegistry{}
+		server := httptest.NewServer(registry)
+		defer server.Close()
+
+		c := newSyncClient(t, ClientOpts{
+			AppID:       "sync",
+			Dev:         BoolPtr(true),
+			RegisterURL: StrPtr(server.URL),
+		}, map[string]string{"fn": "Function"})
+
+		opts := ServeOpts{
+			Origin:      StrPtr("https://example.com"),
+			Path:        StrPtr("/api/inngest"),
+			SyncOnStart: true,
+		}
+		h := c.ServeWithOpts(opts)
+
+		// Functions are commonly created after the handler, and are included as
+		// the sync starts on the first request.
+		_, err := CreateFunction(
+			c,
+			FunctionOpts{ID: "later"},
+			EventTrigger("test/event", nil),
+			func(ctx context.Context, input Input[any]) (any, error) {
+				return nil, nil
+			},
+		)
+		require.NoError(t, err)
+		time.Sleep(50 * time.Millisecond)
+		require.Empty(t, registry.requests())
+
+		c.(HealthChecker).HealthHandler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		require.Eventually(t, func() bool {
+			return c.(*apiClient).h.health().LastSync != nil
+		}, time.Second, 10*time.Millisecond)
+
+		// Serving again doesn't sync again.
+		_ = c.ServeWithOpts(opts)
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/inngest", nil))
+		time.Sleep(50 * time.Millisecond)
+		require.Len(t, registry.requests(), 1)
+		require.Equal(t, "https://example.com/api/inngest", registry.requests()[0].URL)
+
+		var slugs []string
+		for _, f := range registry.requests()[0].Functions {
+			slugs = append(slugs, f.Slug)
+		}
+		require.ElementsMatch(t, []string{"sync-fn", "sync-later"}, slugs)
+	})
+
+	t.Run("multiple apps", func(t *testing.T) {
+		registry := &syncRegistry{}
+		server := httptest.NewServer(registry)
+		defer server.Close()
+
+		h, err := MultiAppHandler([]Client{
+			newMultiAppClient(t, "billing", server.URL),
+			newMultiAppClient(t, "reports", server.URL),
+		}, ServeOpts{
+			Origin:      StrPtr("https://example.com"),
+			Path:        StrPtr("/api/inngest"),
+			SyncOnStart: true,
+		})
+		require.NoError(t, err)
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/inngest", nil))
+
+		require.Eventually(t, func() bool {
+			return len(registry.requests()) == 2
+		}, time.Second, 10*time.Millisecond)
+
+		urls := map[string]string{}
+		for _, req := range registry.requests() {
+			urls[req.AppName] = req.URL
+		}
+		require.Equal(t, map[string]string{
+			"billing": "https://example.com/api/inngest?appId=billing",
+			"reports": "https://example.com/api/inngest?appId=reports",
+		}, urls)
+	})
+}
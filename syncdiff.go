@@ -0,0 +1,187 @@
+package inngestgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/inngest/inngestgo/api"
+	"github.com/inngest/inngestgo/internal/fn"
+)
+
+// SyncDiff describes how a client's functions differ from those last synced to
+// Inngest.
+type SyncDiff struct {
+	// Added lists the IDs of functions which haven't been synced.
+	Added []string
+	// Removed lists the IDs of synced functions which the client no longer
+	// serves.
+	Removed []string
+	// Changed lists the functions whose config differs from the synced config.
+	Changed []FunctionDiff
+}
+
+// Empty returns whether the client's functions match the synced functions.
+func (d SyncDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// FunctionDiff describes how a function's config differs from its synced config.
+type FunctionDiff struct {
+	// ID is the function's fully-qualified ID.
+	ID string
+	// Fields lists the config fields which differ, sorted by path.
+	Fields []FieldDiff
+}
+
+// FieldDiff is a single config field which differs from the synced config.
+type FieldDiff struct {
+	// Path is the path to the field within the function's JSON config, eg.
+	// "triggers[0].event".
+	Path string
+	// Synced is the field's synced value, or nil if it's been added.
+	Synced any
+	// Local is the field's value in the client, or nil if it's been removed.
+	Local any
+}
+
+// SyncDiff compares the client's functions with those last synced to Inngest,
+// eg. to check whether a deploy needs syncing in CI.  Functions are rendered
+// using the configured serve URL, though the URLs that steps are served from
+// aren't compared.
+func (a apiClient) SyncDiff(ctx context.Context) (SyncDiff, error) {
+	appURL, err := configuredURL(a.h.handlerOpts)
+	if err != nil {
+		return SyncDiff{}, err
+	}
+
+	a.h.l.RLock()
+	configs, err := createFunctionConfigs(a.h.appName, a.h.funcs, *appURL, false)
+	a.h.l.RUnlock()
+	if err != nil {
+		return SyncDiff{}, fmt.Errorf("error creating function configs: %w", err)
+	}
+
+	synced, err := a.restAPI(ctx).ListAppFunctions(ctx, a.h.appName)
+	if err != nil && !errors.Is(err, api.ErrNotFound) {
+		return SyncDiff{}, fmt.Errorf("error fetching synced functions: %w", err)
+	}
+
+	return diffFunctionConfigs(configs, synced)
+}
+
+// diffFunctionConfigs compares local function configs with the synced configs.
+func diffFunctionConfigs(local []fn.SyncConfig, synced []json.RawMessage) (SyncDiff, error) {
+	syncedByID := map[string]any{}
+	for _, byt := range synced {
+		var config map[string]any
+		if err := json.Unmarshal(byt, &config); err != nil {
+			return SyncDiff{}, fmt.Errorf("error decoding synced function: %w", err)
+		}
+		id, _ := config["id"].(string)
+		deleteRuntimeURLs(config)
+		syncedByID[id] = config
+	}
+
+	diff := SyncDiff{}
+	for _, config := range local {
+		// Round-trip local configs through JSON so that they're compared in the
+		// same form as synced configs.
+		byt, err := json.Marshal(config)
+		if err != nil {
+			return SyncDiff{}, fmt.Errorf("error encoding function config: %w", err)
+		}
+		var localConfig map[string]any
+		if err := json.Unmarshal(byt, &localConfig); err != nil {
+			return SyncDiff{}, fmt.Errorf("error encoding function config: %w", err)
+		}
+		deleteRuntimeURLs(localConfig)
+
+		syncedConfig, ok := syncedByID[config.Slug]
+		if !ok {
+			diff.Added = append(diff.Added, config.Slug)
+			continue
+		}
+		delete(syncedByID, config.Slug)
+
+		if fields := diffJSON("", syncedConfig, localConfig, nil); len(fields) > 0 {
+			slices.SortFunc(fields, func(a, b FieldDiff) int {
+				return strings.Compare(a.Path, b.Path)
+			})
+			diff.Changed = append(diff.Changed, FunctionDiff{ID: config.Slug, Fields: fields})
+		}
+	}
+	for id := range syncedByID {
+		diff.Removed = append(diff.Removed, id)
+	}
+	slices.Sort(diff.Removed)
+	return diff, nil
+}
+
+// deleteRuntimeURLs removes the URLs that each step is served from within a
+// decoded function config.  These depend on the URL used when syncing rather
+// than the function's config, so aren't compared.
+func deleteRuntimeURLs(config map[string]any) {
+	steps, _ := config["steps"].(map[string]any)
+	for _, step := range steps {
+		if step, ok := step.(map[string]any); ok {
+			if runtime, ok := step["runtime"].(map[string]any); ok {
+				delete(runtime, "url")
+			}
+		}
+	}
+}
+
+// diffJSON appends the differences between two decoded JSON values, recursing
+// into objects and arrays.
+func diffJSON(path string, synced, local any, fields []FieldDiff) []FieldDiff {
+	switch s := synced.(type) {
+	case map[string]any:
+		l, ok := local.(map[string]any)
+		if !ok {
+			break
+		}
+		for k, v := range s {
+			fields = diffJSON(joinPath(path, k), v, l[k], fields)
+		}
+		for k, v := range l {
+			if _, ok := s[k]; !ok {
+				fields = diffJSON(joinPath(path, k), nil, v, fields)
+			}
+		}
+		return fields
+	case []any:
+		l, ok := local.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < max(len(s), len(l)); i++ {
+			var sv, lv any
+			if i < len(s) {
+				sv = s[i]
+			}
+			if i < len(l) {
+				lv = l[i]
+			}
+			fields = diffJSON(path+"["+strconv.Itoa(i)+"]", sv, lv, fields)
+		}
+		return fields
+	}
+
+	if !reflect.DeepEqual(synced, local) {
+		fields = append(fields, FieldDiff{Path: path, Synced: synced, Local: local})
+	}
+	return fields
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,177 @@
+package inngestgo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/inngest/inngestgo/internal/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newSyncClient(t *testing.T, opts ClientOpts, fns map[string]string) Client {
+	c, err := NewClient(opts)
+	require.NoError(t, err)
+	for id, name := range fns {
+		_, err = CreateFunction(
+			c,
+			FunctionOpts{ID: id, Name: name},
+			EventTrigger("test/event", nil),
+			func(ctx context.Context, input Input[any]) (any, error) {
+				return nil, nil
+			},
+		)
+		require.NoError(t, err)
+	}
+	return c
+}
+
+type transportFunc func(req *http.Request) (*http.Response, error)
+
+func (f transportFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestSyncIdempotencyKey(t *testing.T) {
+	r := require.New(t)
+
+	var keys []string
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body types.RegisterRequest
+		_ = json.NewDecoder(req.Body).Decode(&body)
+		keys = append(keys, body.IdempotencyKey)
+	}))
+	defer registry.Close()
+
+	appURL, err := url.Parse("https://example.com/api/inngest")
+	r.NoError(err)
+	opts := ClientOpts{
+		AppID:       "sync",
+		Dev:         BoolPtr(true),
+		RegisterURL: StrPtr(registry.URL),
+		URL:         appURL,
+	}
+	c := newSyncClient(t, opts, map[string]string{"fn": "Function"})
+	server := httptest.NewServer(c.Serve())
+	defer server.Close()
+
+	sync := func(host string) {
+		req, err := http.NewRequest(http.MethodPut, server.URL, nil)
+		r.NoError(err)
+		req.Host = host
+		resp, err := http.DefaultClient.Do(req)
+		r.NoError(err)
+		_ = resp.Body.Close()
+		r.Equal(http.StatusOK, resp.StatusCode)
+	}
+
+	// Replicas reached through different hosts share a key when registering
+	// the same URL.
+	sync("replica-a.internal")
+	sync("replica-b.internal")
+	opts.AppVersion = StrPtr("v2")
+	r.NoError(c.SetOptions(opts))
+	sync("replica-a.internal")
+
+	// Moving the app to a new URL changes the key, even without code changes.
+	opts.URL, err = url.Parse("https://new.example.com/api/inngest")
+	r.NoError(err)
+	r.NoError(c.SetOptions(opts))
+	sync("replica-a.internal")
+
+	// Without a configured URL, each host is registered separately.
+	opts.URL = nil
+	r.NoError(c.SetOptions(opts))
+	sync("replica-a.internal")
+	sync("replica-b.internal")
+
+	r.Len(keys, 6)
+	r.NotEmpty(keys[0])
+	r.Equal(keys[0], keys[1])
+	r.NotEqual(keys[1], keys[2])
+	r.NotEqual(keys[2], keys[3])
+	r.NotEqual(keys[4], keys[5])
+}
+
+func TestSyncDiff(t *testing.T) {
+	r := require.New(t)
+	appURL, err := url.Parse("https://example.com/api/inngest")
+	r.NoError(err)
+
+	// Render the synced config from an earlier version of the app.
+	synced := newSyncClient(t, ClientOpts{AppID: "diff", URL: appURL}, map[string]string{
+		"changed": "Old name",
+		"removed": "Removed",
+	})
+	// The app was synced from a different URL, which isn't reported as a change.
+	syncedURL, err := url.Parse("https://old.example.com/inngest")
+	r.NoError(err)
+	syncedConfigs, err := createFunctionConfigs("diff", synced.(*apiClient).h.funcs, *syncedURL, false)
+	r.NoError(err)
+
+	var apps int
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/v1/apps/diff/functions":
+			apps++
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": syncedConfigs})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer api.Close()
+
+	// Requests use the client's HTTP client.
+	var transported int
+	httpClient := &http.Client{Transport: transportFunc(func(req *http.Request) (*http.Response, error) {
+		transported++
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+
+	c := newSyncClient(t, ClientOpts{
+		AppID:      "diff",
+		URL:        appURL,
+		APIBaseURL: StrPtr(api.URL),
+		HTTPClient: httpClient,
+	}, map[string]string{
+		"changed": "New name",
+		"added":   "Added",
+	})
+
+	diff, err := c.(Syncer).SyncDiff(context.Background())
+	r.NoError(err)
+	r.Equal(1, apps)
+	r.Equal(1, transported)
+	r.False(diff.Empty())
+	r.Equal([]string{"diff-added"}, diff.Added)
+	r.Equal([]string{"diff-removed"}, diff.Removed)
+	r.Equal([]FunctionDiff{{
+		ID: "diff-changed",
+		Fields: []FieldDiff{
+			{Path: "name", Synced: "Old name", Local: "New name"},
+			{Path: "steps.step.name", Synced: "Old name", Local: "New name"},
+		},
+	}}, diff.Changed)
+
+	t.Run("unsynced app", func(t *testing.T) {
+		c := newSyncClient(t, ClientOpts{
+			AppID:      "unsynced",
+			URL:        appURL,
+			APIBaseURL: StrPtr(api.URL),
+		}, map[string]string{"fn": "Function"})
+
+		diff, err := c.(Syncer).SyncDiff(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, SyncDiff{Added: []string{"unsynced-fn"}}, diff)
+	})
+
+	t.Run("no URL", func(t *testing.T) {
+		t.Setenv("INNGEST_SERVE_HOST", "")
+		c := newSyncClient(t, ClientOpts{AppID: "diff"}, nil)
+		_, err := c.(Syncer).SyncDiff(context.Background())
+		require.ErrorContains(t, err, "no serve URL configured")
+	})
+}
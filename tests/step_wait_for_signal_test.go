@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/inngest/inngest/pkg/enums"
+	"github.com/inngest/inngestgo"
+	"github.com/inngest/inngestgo/step"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepWaitForSignal(t *testing.T) {
+	devEnv(t)
+
+	type Approval struct {
+		Approved bool `json:"approved"`
+	}
+	approval := inngestgo.NewSignalType[Approval]()
+
+	t.Run("signal", func(t *testing.T) {
+		ctx := context.Background()
+		r := require.New(t)
+
+		c, err := inngestgo.NewClient(inngestgo.ClientOpts{
+			AppID: randomSuffix("my-app"),
+		})
+		r.NoError(err)
+
+		var runID atomic.Value
+		var stepResult Approval
+		var stepError error
+		eventName := randomSuffix("event")
+		signal := randomSuffix("signal")
+		_, err = inngestgo.CreateFunction(
+			c,
+			inngestgo.FunctionOpts{
+				ID:      "fn",
+				Retries: inngestgo.IntPtr(0),
+			},
+			inngestgo.EventTrigger(eventName, nil),
+			func(ctx context.Context, input inngestgo.Input[any]) (any, error) {
+				runID.Store(input.InputCtx.RunID)
+				stepResult, stepError = approval.WaitFor(ctx, "a", signal, 10*time.Second)
+				return stepResult, stepError
+			},
+		)
+		r.NoError(err)
+
+		server, sync := serve(t, c)
+		defer server.Close()
+		r.NoError(sync())
+
+		_, err = c.Send(ctx, inngestgo.Event{Name: eventName})
+		r.NoError(err)
+
+		// Sending the signal fails until the run is waiting for it.
+		var signalledRunID string
+		r.EventuallyWithT(func(ct *assert.CollectT) {
+			a := assert.New(ct)
+			signalledRunID, err = approval.Send(ctx, c, signal, Approval{Approved: true})
+			a.NoError(err)
+		}, 5*time.Second, 100*time.Millisecond)
+
+		waitForRun(t, &runID, enums.RunStatusCompleted.String())
+		r.NoError(stepError)
+		r.True(stepResult.Approved)
+		r.Equal(runID.Load(), signalledRunID)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		r := require.New(t)
+
+		c, err := inngestgo.NewClient(inngestgo.ClientOpts{
+			AppID: randomSuffix("my-app"),
+		})
+		r.NoError(err)
+
+		_, err = approval.Send(context.Background(), c, randomSuffix("signal"), Approval{})
+		r.ErrorIs(err, inngestgo.ErrSignalNotFound)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		ctx := context.Background()
+		r := require.New(t)
+
+		c, err := inngestgo.NewClient(inngestgo.ClientOpts{
+			AppID: randomSuffix("my-app"),
+		})
+		r.NoError(err)
+
+		var runID atomic.Value
+		var stepError error
+		eventName := randomSuffix("event")
+		_, err = inngestgo.CreateFunction(
+			c,
+			inngestgo.FunctionOpts{
+				ID:      "fn",
+				Retries: inngestgo.IntPtr(0),
+			},
+			inngestgo.EventTrigger(eventName, nil),
+			func(ctx context.Context, input inngestgo.Input[any]) (any, error) {
+				runID.Store(input.InputCtx.RunID)
+				_, stepError = approval.WaitFor(ctx, "a", randomSuffix("signal"), time.Second)
+				return nil, stepError
+			},
+		)
+		r.NoError(err)
+
+		server, sync := serve(t, c)
+		defer server.Close()
+		r.NoError(sync())
+
+		_, err = c.Send(ctx, inngestgo.Event{Name: eventName})
+		r.NoError(err)
+
+		waitForRun(t, &runID, enums.RunStatusFailed.String())
+		r.ErrorIs(stepError, step.ErrSignalNotReceived)
+	})
+}
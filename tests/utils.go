@@ -1,7 +1,7 @@
 package tests
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,6 +13,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/inngest/inngestgo"
+	"github.com/inngest/inngestgo/api"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -27,28 +28,18 @@ type Run struct {
 }
 
 func getRun(id string) (*Run, error) {
-	res, err := http.Get(fmt.Sprintf("http://localhost:8288/v1/runs/%s", id))
+	c := api.New(api.Opts{BaseURL: "http://localhost:8288"})
+	run, err := c.GetRun(context.Background(), id)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		_ = res.Body.Close()
-	}()
 
-	byt, err := io.ReadAll(res.Body)
+	output, err := api.RunOutput[any](run)
 	if err != nil {
 		return nil, err
 	}
 
-	var body struct {
-		Data Run `json:"data"`
-	}
-	err = json.Unmarshal(byt, &body)
-	if err != nil {
-		return nil, err
-	}
-
-	return &body.Data, nil
+	return &Run{Output: output, Status: string(run.Status)}, nil
 }
 
 func waitForRun(t *testing.T, id *atomic.Value, status string) *Run {